@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+const accessUsage = "access list | add <block|allow> <username|glob|regex|key|cidr> <value> [--reason text] [--expires 24h|YYYY-MM-DD] | remove <id> | check <username> [--key fingerprint] [--ip address]"
+
+// runAccess dispatches the access rule subcommands, changes reach a running
+// server within a few seconds
+func runAccess(configPath string, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s\n", os.Args[0], accessUsage)
+		return errUsage
+	}
+
+	var run func(store *stats.Store, allowlistOnly bool, args []string) error
+	switch args[0] {
+	case "list":
+		run = runAccessList
+	case "add":
+		run = runAccessAdd
+	case "remove":
+		run = runAccessRemove
+	case "check":
+		run = runAccessCheck
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: %s %s\n", os.Args[0], accessUsage)
+		return errUsage
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(config)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = store.Close()
+	}()
+
+	return run(store, config.AllowlistOnly, args[1:])
+}
+
+// runAccessList prints every access rule
+func runAccessList(store *stats.Store, allowlistOnly bool, args []string) error {
+	fs := newFlagSet("access list", "access list")
+	if positional, err := parseFlags(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	rules, err := store.ListAccessRules()
+	if err != nil {
+		return err
+	}
+
+	if allowlistOnly {
+		fmt.Println("Allowlist mode: only connections matching an allow rule are admitted")
+		fmt.Println()
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "ID\tLIST\tKIND\tVALUE\tEXPIRES\tREASON")
+
+	for _, rule := range rules {
+		expires := "never"
+		if rule.Expired(now) {
+			expires = "expired"
+		} else if !rule.ExpiresAt.IsZero() {
+			expires = rule.ExpiresAt.Local().Format("2006-01-02 15:04")
+		}
+
+		_, _ = fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\n", rule.ID, rule.List, rule.Kind, rule.Value, expires, valueOr(rule.Reason, "-"))
+	}
+
+	return w.Flush()
+}
+
+// runAccessAdd adds an access rule
+func runAccessAdd(store *stats.Store, allowlistOnly bool, args []string) error {
+	fs := newFlagSet("access add", "access add <block|allow> <username|glob|regex|key|cidr> <value> [--reason text] [--expires 24h|YYYY-MM-DD]\n\n"+
+		"An allow rule only overrides block rules of the same identity: username, glob and regex\n"+
+		"rules override each other, key and cidr rules only override rules of their own kind.\n"+
+		"Allowing a username does not admit it from a blocked address range or with a blocked key.\n")
+	reason := fs.String("reason", "", "why the rule was added")
+	expires := fs.String("expires", "", "duration like 24h or date the rule expires at, never if empty")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 3 {
+		fs.Usage()
+		return errUsage
+	}
+
+	rule := stats.AccessRule{
+		List:   stats.AccessList(positional[0]),
+		Kind:   stats.AccessRuleKind(positional[1]),
+		Value:  positional[2],
+		Reason: *reason,
+	}
+
+	if *expires != "" {
+		if rule.ExpiresAt, err = parseExpiry(*expires); err != nil {
+			return err
+		}
+	}
+
+	added, err := store.AddAccessRule(rule)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Added rule %d: %s %s %s\n", added.ID, added.List, added.Kind, added.Value)
+	return nil
+}
+
+// runAccessRemove removes an access rule
+func runAccessRemove(store *stats.Store, allowlistOnly bool, args []string) error {
+	fs := newFlagSet("access remove", "access remove <id>")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	id, err := strconv.ParseInt(positional[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid rule id %q", positional[0])
+	}
+
+	if err := store.RemoveAccessRule(id); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed rule %d\n", id)
+	return nil
+}
+
+// runAccessCheck prints whether a connection would be admitted and by which rule
+func runAccessCheck(store *stats.Store, allowlistOnly bool, args []string) error {
+	fs := newFlagSet("access check", "access check <username> [--key fingerprint] [--ip address]")
+	key := fs.String("key", "", "SSH key fingerprint of the connection")
+	ip := fs.String("ip", "", "remote address of the connection")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	request := stats.AccessRequest{Username: positional[0], SSHKeyFingerprint: *key}
+	if *ip != "" {
+		if request.IP = net.ParseIP(*ip); request.IP == nil {
+			return fmt.Errorf("invalid IP address %q", *ip)
+		}
+	}
+
+	rules, err := store.LoadAccessRules()
+	if err != nil {
+		return err
+	}
+
+	allowed, rule := rules.Check(request, allowlistOnly)
+
+	verdict := "blocked"
+	if allowed {
+		verdict = "allowed"
+	}
+
+	switch {
+	case rule != nil:
+		fmt.Printf("%s by rule %d: %s %s %s\n", verdict, rule.ID, rule.List, rule.Kind, rule.Value)
+	case allowed:
+		fmt.Println("allowed, no rule matches")
+	default:
+		fmt.Println("blocked, no allow rule matches in allowlist mode")
+	}
+
+	return nil
+}
+
+// parseExpiry parses a duration from now or a date or time to expire at
+func parseExpiry(value string) (time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		if duration <= 0 {
+			return time.Time{}, errors.New("expiry duration must be positive")
+		}
+
+		return time.Now().Add(duration), nil
+	}
+
+	if date, err := time.ParseInLocation(stats.DateFormat, value, time.Local); err == nil {
+		return date, nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+
+	return time.Time{}, fmt.Errorf("invalid expiry %q, expected a duration like 24h, a date like 2026-01-31 or an RFC 3339 time", value)
+}
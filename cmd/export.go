@@ -0,0 +1,199 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+// exportedUser is a user in the JSON export
+type exportedUser struct {
+	Username          string         `json:"username"`
+	SSHKeyFingerprint string         `json:"ssh_key_fingerprint"`
+	GamesPlayed       int            `json:"games_played"`
+	GamesWon          int            `json:"games_won"`
+	GamesLost         int            `json:"games_lost"`
+	CurrentStreak     int            `json:"current_streak"`
+	MaxStreak         int            `json:"max_streak"`
+	GuessDistribution [6]int         `json:"guess_distribution"`
+	LastWordDate      string         `json:"last_word_date,omitempty"`
+	Games             []exportedGame `json:"games"`
+}
+
+// exportedGame is a finished game in the JSON export
+type exportedGame struct {
+	Date       string     `json:"date"`
+	Mode       string     `json:"mode"`
+	HardMode   bool       `json:"hard_mode"`
+	Won        bool       `json:"won"`
+	Guesses    int        `json:"guesses"`
+	StartedAt  *time.Time `json:"started_at,omitempty"`
+	FinishedAt time.Time  `json:"finished_at"`
+	Imported   bool       `json:"imported,omitempty"`
+}
+
+// runStats exports the stats and game history of every user
+func runStats(configPath string, args []string) error {
+	fs := newFlagSet("stats export", "stats export [--format json|csv] [--output path]")
+	format := fs.String("format", "json", "json for users with their games, csv for one row per game")
+	output := fs.String("output", "", "file to write to instead of stdout")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 || positional[0] != "export" || (*format != "json" && *format != "csv") {
+		fs.Usage()
+		return errUsage
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(config)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = store.Close()
+	}()
+
+	users, err := exportUsers(store)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return fmt.Errorf("failed to create export file: %w", err)
+		}
+
+		defer func() {
+			_ = file.Close()
+		}()
+
+		w = file
+	}
+
+	if *format == "csv" {
+		err = writeGamesCSV(w, users)
+	} else {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(users)
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to write export: %w", err)
+	}
+
+	if *output != "" {
+		config.Logger.Info("Exported stats", "users", len(users), "path", *output)
+	}
+
+	return nil
+}
+
+// exportUsers loads the stats and games of every user
+func exportUsers(store *stats.Store) ([]exportedUser, error) {
+	users, err := store.ListUsers("")
+	if err != nil {
+		return nil, err
+	}
+
+	exported := make([]exportedUser, 0, len(users))
+	for _, user := range users {
+		userStats, err := store.GetUserStats(user.Username, user.SSHKeyFingerprint)
+		if err != nil {
+			return nil, err
+		}
+
+		games, err := store.GetGames(user.Username, user.SSHKeyFingerprint)
+		if err != nil {
+			return nil, err
+		}
+
+		entry := exportedUser{
+			Username:          user.Username,
+			SSHKeyFingerprint: user.SSHKeyFingerprint,
+			GamesPlayed:       userStats.GamesPlayed,
+			GamesWon:          userStats.GamesWon,
+			GamesLost:         userStats.GamesLost,
+			CurrentStreak:     userStats.CurrentStreak,
+			MaxStreak:         userStats.MaxStreak,
+			GuessDistribution: userStats.GuessDistribution,
+			LastWordDate:      userStats.LastWordDate,
+			Games:             make([]exportedGame, 0, len(games)),
+		}
+
+		for _, game := range games {
+			exportGame := exportedGame{
+				Date:       game.WordDate,
+				Mode:       string(game.Mode),
+				HardMode:   game.HardMode,
+				Won:        game.Won,
+				Guesses:    game.Guesses,
+				FinishedAt: game.FinishedAt,
+				Imported:   game.Imported,
+			}
+
+			if !game.StartedAt.IsZero() {
+				exportGame.StartedAt = &game.StartedAt
+			}
+
+			entry.Games = append(entry.Games, exportGame)
+		}
+
+		exported = append(exported, entry)
+	}
+
+	return exported, nil
+}
+
+// writeGamesCSV writes one row per finished game
+func writeGamesCSV(w io.Writer, users []exportedUser) error {
+	writer := csv.NewWriter(w)
+	header := []string{"username", "ssh_key_fingerprint", "date", "mode", "hard_mode", "won", "guesses", "started_at", "finished_at"}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+
+	for _, user := range users {
+		for _, game := range user.Games {
+			startedAt := ""
+			if game.StartedAt != nil {
+				startedAt = game.StartedAt.Format(time.RFC3339)
+			}
+
+			row := []string{
+				user.Username,
+				user.SSHKeyFingerprint,
+				game.Date,
+				game.Mode,
+				strconv.FormatBool(game.HardMode),
+				strconv.FormatBool(game.Won),
+				strconv.Itoa(game.Guesses),
+				startedAt,
+				game.FinishedAt.Format(time.RFC3339),
+			}
+
+			if err := writer.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// runHealthcheck queries the health or readiness endpoint of a running server,
+// for use as a Docker HEALTHCHECK
+func runHealthcheck(configPath string, args []string) error {
+	fs := newFlagSet("healthcheck", "healthcheck [--ready] [--timeout 5s]")
+	ready := fs.Bool("ready", false, "check readiness instead of health")
+	timeout := fs.Duration("timeout", 5*time.Second, "timeout of the request")
+	if positional, err := parseFlags(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	if config.HTTPAddr == "" {
+		return errors.New("the HTTP listener is disabled, set http.addr or WORDLE_SSH_HTTP_ADDR")
+	}
+
+	host, port, err := net.SplitHostPort(config.HTTPAddr)
+	if err != nil {
+		return fmt.Errorf("invalid HTTP address %q: %w", config.HTTPAddr, err)
+	}
+
+	// A listener on all interfaces is reached on loopback
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+
+	path := "/healthz"
+	if *ready {
+		path = "/readyz"
+	}
+
+	client := &http.Client{Timeout: *timeout}
+	resp, err := client.Get("http://" + net.JoinHostPort(host, port) + path)
+	if err != nil {
+		return fmt.Errorf("failed to reach server: %w", err)
+	}
+
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+	message := strings.TrimSpace(string(body))
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s returned %s: %s", path, resp.Status, message)
+	}
+
+	fmt.Println(message)
+	return nil
+}
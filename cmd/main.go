@@ -1,18 +1,139 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"runtime/debug"
+	_ "time/tzdata" // Embed timezone data for per-user timezones in slim images
 
 	"github.com/charmbracelet/log"
 	"github.com/f-gillmann/wordle-ssh/internal/server"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
 )
 
+// version is set at build time with -ldflags "-X main.version=..."
+var version = "dev"
+
+// errUsage is returned when a command is called with invalid arguments,
+// the usage has already been printed
+var errUsage = errors.New("invalid usage")
+
+// command is a subcommand of the wordle-ssh binary
+type command struct {
+	name        string
+	usage       string
+	description string
+	run         func(configPath string, args []string) error
+}
+
+// commands returns every subcommand in the order they are listed by help
+func commands() []command {
+	return []command{
+		{name: "serve", usage: "serve", description: "Start the SSH server (default)", run: runServe},
+		{name: "migrate", usage: "migrate", description: "Apply pending database migrations", run: runMigrate},
+		{name: "user", usage: "user list|show|delete|merge", description: "Manage users", run: runUser},
+		{name: "access", usage: "access list|add|remove|check", description: "Manage the block and allow rules for connections", run: runAccess},
+		{name: "words", usage: "words fetch [--date YYYY-MM-DD]", description: "Fetch and store a daily word", run: runWords},
+		{name: "stats", usage: "stats export [--format json|csv] [--output path]", description: "Export the statistics of all users", run: runStats},
+		{name: "healthcheck", usage: "healthcheck [--ready]", description: "Check the health or readiness of a running server", run: runHealthcheck},
+		{name: "config", usage: "config check", description: "Validate and print the effective configuration", run: runConfig},
+		{name: "version", usage: "version", description: "Print the version", run: runVersion},
+	}
+}
+
 func main() {
-	// Load configuration
-	config := server.LoadConfigFromEnv()
+	configPath := flag.String("config", "", "path to a TOML config file (default $WORDLE_SSH_CONFIG)")
+	flag.Usage = usage
+	flag.Parse()
+
+	// Without a command the server is started
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"serve"}
+	}
+
+	for _, cmd := range commands() {
+		if cmd.name != args[0] {
+			continue
+		}
 
-	// Initialize logger
-	logger := log.NewWithOptions(os.Stderr, log.Options{
+		err := cmd.run(*configPath, args[1:])
+		if errors.Is(err, errUsage) {
+			os.Exit(2)
+		}
+
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Error:", err)
+			os.Exit(1)
+		}
+
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown command %q\n\n", args[0])
+	usage()
+	os.Exit(2)
+}
+
+// usage prints the global flags and the available commands
+func usage() {
+	out := flag.CommandLine.Output()
+	fmt.Fprintf(out, "Usage: %s [--config path] [command]\n\n", os.Args[0])
+	fmt.Fprintln(out, "Commands:")
+
+	width := 0
+	for _, cmd := range commands() {
+		width = max(width, len(cmd.usage))
+	}
+
+	for _, cmd := range commands() {
+		fmt.Fprintf(out, "  %-*s  %s\n", width, cmd.usage, cmd.description)
+	}
+
+	fmt.Fprintln(out, "\nFlags:")
+	flag.PrintDefaults()
+}
+
+// newFlagSet creates the flag set of a command, printing its usage on errors
+func newFlagSet(name string, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: %s %s\n", os.Args[0], usage)
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// parseFlags parses flags placed anywhere between the positional arguments of
+// a command and returns the positional arguments
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// loadConfig loads the configuration and sets up its logger
+func loadConfig(configPath string) (server.Config, error) {
+	config, err := server.LoadConfig(configPath)
+	if err != nil {
+		return config, err
+	}
+
+	config.Logger = log.NewWithOptions(os.Stderr, log.Options{
 		ReportTimestamp: true,
 		ReportCaller:    config.LogLevel == log.DebugLevel,
 		TimeFormat:      "2006/01/02 15:04:05",
@@ -20,18 +141,119 @@ func main() {
 		Level:           config.LogLevel,
 	})
 
-	// Set the logger in config
-	config.Logger = logger
+	return config, nil
+}
+
+// openStore opens the stats database of the configuration, applying pending migrations
+func openStore(config server.Config) (*stats.Store, error) {
+	store, err := stats.NewStore(config.DBPath, config.Logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open stats database: %w", err)
+	}
+
+	store.SetLeaderboardConfig(stats.LeaderboardConfig{
+		OptIn:    config.LeaderboardOptIn,
+		MinGames: config.LeaderboardMinGames,
+	})
+
+	return store, nil
+}
+
+// runServe starts the SSH server
+func runServe(configPath string, args []string) error {
+	fs := newFlagSet("serve", "serve")
+	if positional, err := parseFlags(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	config.Logger.Info("Starting wordle-ssh", "version", version)
 
 	// Create and start the server
 	srv, err := server.New(config)
 	if err != nil {
-		logger.Fatal("Failed to create server", "error", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to create server: %w", err)
 	}
 
 	if err := srv.Start(); err != nil {
-		logger.Fatal("Failed to start server", "error", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to start server: %w", err)
+	}
+
+	return nil
+}
+
+// runMigrate applies pending migrations and prints the schema version
+func runMigrate(configPath string, args []string) error {
+	fs := newFlagSet("migrate", "migrate")
+	if positional, err := parseFlags(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		fs.Usage()
+		return errUsage
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(config)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = store.Close()
+	}()
+
+	schemaVersion, err := store.SchemaVersion()
+	if err != nil {
+		return err
 	}
+
+	fmt.Printf("Database %s is at schema version %d\n", config.DBPath, schemaVersion)
+	return nil
+}
+
+// runConfig validates and prints the effective configuration
+func runConfig(configPath string, args []string) error {
+	fs := newFlagSet("config", "config check")
+	if positional, err := parseFlags(fs, args); err != nil {
+		return err
+	} else if len(positional) != 1 || positional[0] != "check" {
+		fs.Usage()
+		return errUsage
+	}
+
+	config, err := server.LoadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	return config.WriteTOML(os.Stdout)
+}
+
+// runVersion prints the version and the commit it was built from
+func runVersion(configPath string, args []string) error {
+	fmt.Printf("wordle-ssh %s\n", version)
+
+	if info, ok := debug.ReadBuildInfo(); ok {
+		fmt.Printf("go %s\n", info.GoVersion)
+
+		for _, setting := range info.Settings {
+			switch setting.Key {
+			case "vcs.revision", "vcs.time", "vcs.modified":
+				fmt.Printf("%s %s\n", setting.Key, setting.Value)
+			}
+		}
+	}
+
+	return nil
 }
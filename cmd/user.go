@@ -0,0 +1,269 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+const userUsage = "user list [username] | show <username> [--key fingerprint] | delete <username> [--key fingerprint] [--yes] | merge <from> <to> [--from-key fingerprint] [--to-key fingerprint] [--yes]"
+
+// runUser dispatches the user subcommands
+func runUser(configPath string, args []string) error {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s %s\n", os.Args[0], userUsage)
+		return errUsage
+	}
+
+	var run func(store *stats.Store, args []string) error
+	switch args[0] {
+	case "list":
+		run = runUserList
+	case "show":
+		run = runUserShow
+	case "delete":
+		run = runUserDelete
+	case "merge":
+		run = runUserMerge
+	default:
+		fmt.Fprintf(os.Stderr, "Usage: %s %s\n", os.Args[0], userUsage)
+		return errUsage
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(config)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = store.Close()
+	}()
+
+	return run(store, args[1:])
+}
+
+// resolveUser finds the user with a username, the key fingerprint is needed
+// if the username is used with more than one key
+func resolveUser(store *stats.Store, username string, sshKeyFingerprint string) (*stats.User, error) {
+	users, err := store.ListUsers(username)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []stats.User
+	for _, user := range users {
+		if sshKeyFingerprint == "" || user.SSHKeyFingerprint == sshKeyFingerprint {
+			matches = append(matches, user)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		if sshKeyFingerprint != "" {
+			return nil, fmt.Errorf("no user %q with key %s", username, sshKeyFingerprint)
+		}
+		return nil, fmt.Errorf("no user %q", username)
+	case 1:
+		return &matches[0], nil
+	default:
+		keys := make([]string, 0, len(matches))
+		for _, user := range matches {
+			keys = append(keys, "  "+user.SSHKeyFingerprint)
+		}
+		return nil, fmt.Errorf("user %q has several keys, select one with --key:\n%s", username, strings.Join(keys, "\n"))
+	}
+}
+
+// runUserList prints every user, or the keys of one username
+func runUserList(store *stats.Store, args []string) error {
+	fs := newFlagSet("user list", "user list [username]")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) > 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	username := ""
+	if len(positional) == 1 {
+		username = positional[0]
+	}
+
+	users, err := store.ListUsers(username)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "USERNAME\tKEY FINGERPRINT\tPLAYED\tWON\tLAST PLAYED")
+
+	for _, user := range users {
+		lastPlayed := "-"
+		if !user.LastPlayed.IsZero() {
+			lastPlayed = user.LastPlayed.Format("2006-01-02 15:04")
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%s\n", user.Username, user.SSHKeyFingerprint, user.GamesPlayed, user.GamesWon, lastPlayed)
+	}
+
+	return w.Flush()
+}
+
+// runUserShow prints the stats, settings and groups of a user
+func runUserShow(store *stats.Store, args []string) error {
+	fs := newFlagSet("user show", "user show <username> [--key fingerprint]")
+	key := fs.String("key", "", "SSH key fingerprint, needed if the username has several keys")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	user, err := resolveUser(store, positional[0], *key)
+	if err != nil {
+		return err
+	}
+
+	userStats, err := store.GetUserStats(user.Username, user.SSHKeyFingerprint)
+	if err != nil {
+		return err
+	}
+
+	settings, err := store.GetUserSettings(user.Username, user.SSHKeyFingerprint)
+	if err != nil {
+		return err
+	}
+
+	groups, err := store.GetUserGroups(user.Username, user.SSHKeyFingerprint)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Username:         %s\n", user.Username)
+	fmt.Printf("Key fingerprint:  %s\n", user.SSHKeyFingerprint)
+	fmt.Println()
+	fmt.Printf("Games played:     %d\n", userStats.GamesPlayed)
+	fmt.Printf("Games won:        %d (%.1f%%)\n", userStats.GamesWon, userStats.GetWinRate())
+	fmt.Printf("Current streak:   %d\n", userStats.CurrentStreakOn(settings.Today()))
+	fmt.Printf("Max streak:       %d\n", userStats.MaxStreak)
+	fmt.Printf("Last puzzle:      %s\n", valueOr(userStats.LastWordDate, "-"))
+	fmt.Println()
+	fmt.Printf("Timezone:         %s\n", valueOr(settings.Timezone, "server default"))
+	fmt.Printf("Hard mode:        %t\n", settings.HardMode)
+	fmt.Printf("On leaderboards:  %t\n", settings.OnLeaderboard(store.LeaderboardConfig().OptIn))
+
+	if len(groups) > 0 {
+		fmt.Println()
+		fmt.Println("Groups:")
+		for _, group := range groups {
+			role := "member"
+			if group.IsOwner(user.Username, user.SSHKeyFingerprint) {
+				role = "owner"
+			}
+
+			fmt.Printf("  %s (%s, %d members)\n", group.Name, role, group.MemberCount)
+		}
+	}
+
+	return nil
+}
+
+// runUserDelete deletes all data of a user
+func runUserDelete(store *stats.Store, args []string) error {
+	fs := newFlagSet("user delete", "user delete <username> [--key fingerprint] [--yes]")
+	key := fs.String("key", "", "SSH key fingerprint, needed if the username has several keys")
+	yes := fs.Bool("yes", false, "delete without asking for confirmation")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 {
+		fs.Usage()
+		return errUsage
+	}
+
+	user, err := resolveUser(store, positional[0], *key)
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		fmt.Printf("This deletes all data of %s (%s), including %d games.\n", user.Username, user.SSHKeyFingerprint, user.GamesPlayed)
+		fmt.Println("Run again with --yes to delete it.")
+		return nil
+	}
+
+	if err := store.DeleteUserData(user.Username, user.SSHKeyFingerprint); err != nil {
+		return err
+	}
+
+	fmt.Printf("Deleted all data of %s (%s)\n", user.Username, user.SSHKeyFingerprint)
+	return nil
+}
+
+// runUserMerge moves all data of one user to another
+func runUserMerge(store *stats.Store, args []string) error {
+	fs := newFlagSet("user merge", "user merge <from> <to> [--from-key fingerprint] [--to-key fingerprint] [--yes]")
+	fromKey := fs.String("from-key", "", "SSH key fingerprint of the user to merge, needed if the username has several keys")
+	toKey := fs.String("to-key", "", "SSH key fingerprint of the user to keep, needed if the username has several keys")
+	yes := fs.Bool("yes", false, "merge without asking for confirmation")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 2 {
+		fs.Usage()
+		return errUsage
+	}
+
+	from, err := resolveUser(store, positional[0], *fromKey)
+	if err != nil {
+		return err
+	}
+
+	to, err := resolveUser(store, positional[1], *toKey)
+	if err != nil {
+		return err
+	}
+
+	if !*yes {
+		fmt.Printf("This moves all data of %s (%s) to %s (%s) and deletes %s (%s).\n",
+			from.Username, from.SSHKeyFingerprint, to.Username, to.SSHKeyFingerprint, from.Username, from.SSHKeyFingerprint)
+		fmt.Println("Games for puzzle dates both users played are kept from the second user.")
+		fmt.Println("Run again with --yes to merge them.")
+		return nil
+	}
+
+	if err := store.MergeUsers(from.Username, from.SSHKeyFingerprint, to.Username, to.SSHKeyFingerprint); err != nil {
+		return err
+	}
+
+	fmt.Printf("Merged %s (%s) into %s (%s)\n", from.Username, from.SSHKeyFingerprint, to.Username, to.SSHKeyFingerprint)
+	return nil
+}
+
+// valueOr returns the value, or the fallback if it is empty
+func valueOr(value string, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+
+	return value
+}
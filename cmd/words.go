@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/server"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+// runWords fetches the word for a date and stores it, like the server does on first use
+func runWords(configPath string, args []string) error {
+	fs := newFlagSet("words fetch", "words fetch [--date YYYY-MM-DD]")
+	date := fs.String("date", time.Now().Format(stats.DateFormat), "puzzle date to fetch")
+	positional, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	if len(positional) != 1 || positional[0] != "fetch" {
+		fs.Usage()
+		return errUsage
+	}
+
+	if _, err := time.Parse(stats.DateFormat, *date); err != nil {
+		return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", *date)
+	}
+
+	config, err := loadConfig(configPath)
+	if err != nil {
+		return err
+	}
+
+	store, err := openStore(config)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = store.Close()
+	}()
+
+	source, err := server.NewWordSource(config)
+	if err != nil {
+		return fmt.Errorf("failed to configure word source: %w", err)
+	}
+
+	word, fetched, err := server.LoadDailyWord(store, source, *date)
+	if err != nil {
+		return err
+	}
+
+	if !fetched {
+		fmt.Printf("%s #%d %s (already stored from %s)\n", word.Date, word.Number, word.Solution, word.Source)
+		return nil
+	}
+
+	fmt.Printf("%s #%d %s (fetched from %s)\n", word.Date, word.Number, word.Solution, word.Source)
+	return nil
+}
@@ -0,0 +1,262 @@
+// Package engine implements the Wordle rules independent of any user interface
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/f-gillmann/wordle-ssh/internal/wordle"
+)
+
+const (
+	MaxGuesses = 6
+	WordLength = 5
+)
+
+var (
+	// ErrGameOver is returned when guessing after the game has ended
+	ErrGameOver = errors.New("game is over")
+	// ErrWrongLength is returned for guesses that are not WordLength letters long
+	ErrWrongLength = fmt.Errorf("word must be %d letters", WordLength)
+	// ErrNotInWordList is returned for guesses that are not valid words
+	ErrNotInWordList = errors.New("invalid word")
+)
+
+// HardModeError is returned when a guess ignores a revealed hint in hard mode
+type HardModeError struct {
+	Message string
+}
+
+func (e *HardModeError) Error() string {
+	return e.Message
+}
+
+// State is the state of a game
+type State int
+
+const (
+	StatePlaying State = iota
+	StateWon
+	StateLost
+)
+
+// LetterState is the feedback for a single letter
+type LetterState int
+
+const (
+	LetterCorrect LetterState = iota // Green - correct position
+	LetterPresent                    // Yellow - in word but wrong position
+	LetterAbsent                     // Gray - not in word
+)
+
+// LetterResult is the feedback for a letter at one position of a guess
+type LetterResult struct {
+	Letter rune // Lowercase letter
+	State  LetterState
+}
+
+// Feedback is the per-letter feedback for a guess
+type Feedback []LetterResult
+
+// Game is a single game of Wordle
+type Game struct {
+	solution string
+	hardMode bool
+	guesses  []string
+	feedback []Feedback
+	state    State
+	letters  map[rune]LetterState
+}
+
+// New creates a game for the given solution
+func New(solution string, hardMode bool) *Game {
+	return &Game{
+		solution: strings.ToLower(solution),
+		hardMode: hardMode,
+		state:    StatePlaying,
+		letters:  make(map[rune]LetterState),
+	}
+}
+
+// Restore recreates a game by replaying previously accepted guesses
+func Restore(solution string, hardMode bool, guesses []string) (*Game, error) {
+	game := New(solution, hardMode)
+
+	for _, guess := range guesses {
+		if _, err := game.Guess(guess); err != nil {
+			return nil, fmt.Errorf("failed to replay guess %q: %w", guess, err)
+		}
+	}
+
+	return game, nil
+}
+
+// Guess validates and applies a guess and returns its feedback
+func (g *Game) Guess(word string) (Feedback, error) {
+	if g.state != StatePlaying {
+		return nil, ErrGameOver
+	}
+
+	word = strings.ToLower(word)
+	if len([]rune(word)) != WordLength {
+		return nil, ErrWrongLength
+	}
+
+	if !wordle.IsValidWord(word) {
+		return nil, ErrNotInWordList
+	}
+
+	// In hard mode every revealed hint must be used
+	if g.hardMode {
+		if err := g.checkHardMode(word); err != nil {
+			return nil, err
+		}
+	}
+
+	feedback := Evaluate(g.solution, word)
+	g.guesses = append(g.guesses, word)
+	g.feedback = append(g.feedback, feedback)
+
+	// Only update a letter if it's better information than we had
+	for _, result := range feedback {
+		if existing, ok := g.letters[result.Letter]; !ok || result.State < existing {
+			g.letters[result.Letter] = result.State
+		}
+	}
+
+	if word == g.solution {
+		g.state = StateWon
+	} else if len(g.guesses) >= MaxGuesses {
+		g.state = StateLost
+	}
+
+	return feedback, nil
+}
+
+// checkHardMode returns an error describing the first revealed hint the guess does not use
+func (g *Game) checkHardMode(guess string) error {
+	guessLetters := []rune(guess)
+
+	for _, feedback := range g.feedback {
+		// Green letters must stay in place
+		for i, result := range feedback {
+			if result.State == LetterCorrect && guessLetters[i] != result.Letter {
+				return &HardModeError{Message: fmt.Sprintf("%s letter must be %c", ordinal(i+1), unicode.ToUpper(result.Letter))}
+			}
+		}
+
+		// Yellow letters must be included as often as they were revealed
+		required := make(map[rune]int)
+		for _, result := range feedback {
+			if result.State != LetterAbsent {
+				required[result.Letter]++
+			}
+		}
+
+		for _, result := range feedback {
+			if result.State == LetterPresent && strings.Count(guess, string(result.Letter)) < required[result.Letter] {
+				return &HardModeError{Message: fmt.Sprintf("Guess must contain %c", unicode.ToUpper(result.Letter))}
+			}
+		}
+	}
+
+	return nil
+}
+
+// Evaluate computes the feedback for a guess against a solution
+func Evaluate(solution string, guess string) Feedback {
+	solutionLetters := []rune(strings.ToLower(solution))
+	guessLetters := []rune(strings.ToLower(guess))
+	feedback := make(Feedback, len(guessLetters))
+	used := make([]bool, len(solutionLetters))
+
+	for i, letter := range guessLetters {
+		feedback[i] = LetterResult{Letter: letter, State: LetterAbsent}
+	}
+
+	if len(guessLetters) != len(solutionLetters) {
+		return feedback
+	}
+
+	// First pass: mark correct positions
+	for i := range guessLetters {
+		if guessLetters[i] == solutionLetters[i] {
+			feedback[i].State = LetterCorrect
+			used[i] = true
+		}
+	}
+
+	// Second pass: mark present letters
+	for i := range guessLetters {
+		if feedback[i].State == LetterCorrect {
+			continue
+		}
+
+		for j := range solutionLetters {
+			if !used[j] && guessLetters[i] == solutionLetters[j] {
+				feedback[i].State = LetterPresent
+				used[j] = true
+				break
+			}
+		}
+	}
+
+	return feedback
+}
+
+// State returns the current state of the game
+func (g *Game) State() State {
+	return g.state
+}
+
+// IsOver returns whether the game has been won or lost
+func (g *Game) IsOver() bool {
+	return g.state != StatePlaying
+}
+
+// HardMode returns whether the game is played in hard mode
+func (g *Game) HardMode() bool {
+	return g.hardMode
+}
+
+// Solution returns the solution of the game
+func (g *Game) Solution() string {
+	return g.solution
+}
+
+// Guesses returns the accepted guesses
+func (g *Game) Guesses() []string {
+	return append([]string(nil), g.guesses...)
+}
+
+// Feedback returns the feedback for every accepted guess
+func (g *Game) Feedback() []Feedback {
+	return append([]Feedback(nil), g.feedback...)
+}
+
+// GuessCount returns the number of accepted guesses
+func (g *Game) GuessCount() int {
+	return len(g.guesses)
+}
+
+// LetterState returns the best known state of a letter
+func (g *Game) LetterState(letter rune) (LetterState, bool) {
+	state, ok := g.letters[unicode.ToLower(letter)]
+	return state, ok
+}
+
+// ordinal formats a position as 1st, 2nd, 3rd, ...
+func ordinal(n int) string {
+	switch n {
+	case 1:
+		return "1st"
+	case 2:
+		return "2nd"
+	case 3:
+		return "3rd"
+	default:
+		return fmt.Sprintf("%dth", n)
+	}
+}
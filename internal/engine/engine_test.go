@@ -0,0 +1,249 @@
+package engine
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// states returns the letter states of a feedback
+func states(feedback Feedback) []LetterState {
+	result := make([]LetterState, 0, len(feedback))
+	for _, letter := range feedback {
+		result = append(result, letter.State)
+	}
+
+	return result
+}
+
+func TestEvaluate(t *testing.T) {
+	c, p, a := LetterCorrect, LetterPresent, LetterAbsent
+
+	tests := []struct {
+		solution string
+		guess    string
+		want     []LetterState
+	}{
+		{"crane", "crane", []LetterState{c, c, c, c, c}},
+		{"crane", "fjord", []LetterState{a, a, a, p, a}},
+		// Both b are marked, the first one yellow since the second one is green
+		{"abbey", "babes", []LetterState{p, p, c, c, a}},
+		// The solution has one t, so only the first t of the guess is marked
+		{"crate", "toast", []LetterState{p, a, c, a, a}},
+		// A green letter takes precedence over an earlier yellow for the same letter
+		{"slate", "tatty", []LetterState{a, p, a, c, a}},
+		{"CRANE", "Crane", []LetterState{c, c, c, c, c}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.solution+"/"+tt.guess, func(t *testing.T) {
+			got := states(Evaluate(tt.solution, tt.guess))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Evaluate(%q, %q) = %v, want %v", tt.solution, tt.guess, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGuessRejectsInvalidWords(t *testing.T) {
+	game := New("crane", false)
+
+	if _, err := game.Guess("cran"); !errors.Is(err, ErrWrongLength) {
+		t.Errorf("Guess(%q) error = %v, want %v", "cran", err, ErrWrongLength)
+	}
+
+	if _, err := game.Guess("zzzzz"); !errors.Is(err, ErrNotInWordList) {
+		t.Errorf("Guess(%q) error = %v, want %v", "zzzzz", err, ErrNotInWordList)
+	}
+
+	if game.GuessCount() != 0 {
+		t.Errorf("GuessCount() = %d after rejected guesses, want 0", game.GuessCount())
+	}
+}
+
+func TestHardMode(t *testing.T) {
+	tests := []struct {
+		name    string
+		guesses []string
+		guess   string
+		want    string
+	}{
+		{"green letter moved", []string{"trace"}, "slate", "2nd letter must be R"},
+		{"green letter dropped", []string{"trace"}, "track", "5th letter must be E"},
+		{"yellow letter missing", []string{"trace"}, "grade", "Guess must contain C"},
+		{"yellow letter missing after several guesses", []string{"fjord", "trace"}, "brake", "Guess must contain C"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, err := Restore("crane", true, tt.guesses)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+
+			_, err = game.Guess(tt.guess)
+
+			var hardModeErr *HardModeError
+			if !errors.As(err, &hardModeErr) {
+				t.Fatalf("Guess(%q) error = %v, want a HardModeError", tt.guess, err)
+			}
+
+			if hardModeErr.Message != tt.want {
+				t.Errorf("Guess(%q) message = %q, want %q", tt.guess, hardModeErr.Message, tt.want)
+			}
+		})
+	}
+}
+
+func TestHardModeAcceptsHints(t *testing.T) {
+	game, err := Restore("crane", true, []string{"trace"})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if _, err := game.Guess("brace"); err != nil {
+		t.Errorf("Guess(%q) error = %v, want nil", "brace", err)
+	}
+
+	// Without hard mode the hints may be ignored
+	game, err = Restore("crane", false, []string{"trace"})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	if _, err := game.Guess("slate"); err != nil {
+		t.Errorf("Guess(%q) error = %v, want nil", "slate", err)
+	}
+}
+
+func TestWin(t *testing.T) {
+	game := New("crane", false)
+
+	for _, guess := range []string{"slate", "crane"} {
+		if _, err := game.Guess(guess); err != nil {
+			t.Fatalf("Guess(%q) error = %v", guess, err)
+		}
+	}
+
+	if game.State() != StateWon || !game.IsOver() {
+		t.Errorf("State() = %v, want %v", game.State(), StateWon)
+	}
+
+	if _, err := game.Guess("trace"); !errors.Is(err, ErrGameOver) {
+		t.Errorf("Guess() after win error = %v, want %v", err, ErrGameOver)
+	}
+
+	if result := game.Result(); !result.Won || result.Score() != "2/6" {
+		t.Errorf("Result() = won %t score %s, want won 2/6", result.Won, result.Score())
+	}
+}
+
+func TestLossAfterMaxGuesses(t *testing.T) {
+	game := New("crane", false)
+	guesses := []string{"fjord", "nymph", "waltz", "gucks", "vibex", "dopey"}
+
+	for i, guess := range guesses {
+		if game.IsOver() {
+			t.Fatalf("game over after %d guesses, want %d", i, MaxGuesses)
+		}
+
+		if _, err := game.Guess(guess); err != nil {
+			t.Fatalf("Guess(%q) error = %v", guess, err)
+		}
+	}
+
+	if game.State() != StateLost {
+		t.Errorf("State() = %v after %d guesses, want %v", game.State(), MaxGuesses, StateLost)
+	}
+
+	if _, err := game.Guess("crane"); !errors.Is(err, ErrGameOver) {
+		t.Errorf("Guess() after loss error = %v, want %v", err, ErrGameOver)
+	}
+
+	if result := game.Result(); result.Won || result.Score() != "X/6" {
+		t.Errorf("Result() = won %t score %s, want lost X/6", result.Won, result.Score())
+	}
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	tests := []struct {
+		name     string
+		hardMode bool
+		guesses  []string
+	}{
+		{"new game", false, nil},
+		{"in progress", true, []string{"trace", "brace"}},
+		{"won", false, []string{"slate", "crane"}},
+		{"lost", false, []string{"fjord", "nymph", "waltz", "gucks", "vibex", "dopey"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			game, err := Restore("crane", tt.hardMode, tt.guesses)
+			if err != nil {
+				t.Fatalf("Restore() error = %v", err)
+			}
+
+			data, err := json.Marshal(game)
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			var restored Game
+			if err := json.Unmarshal(data, &restored); err != nil {
+				t.Fatalf("Unmarshal(%s) error = %v", data, err)
+			}
+
+			if restored.Solution() != game.Solution() || restored.HardMode() != game.HardMode() || restored.State() != game.State() {
+				t.Errorf("restored game = %q hard %t state %v, want %q hard %t state %v",
+					restored.Solution(), restored.HardMode(), restored.State(), game.Solution(), game.HardMode(), game.State())
+			}
+
+			if !reflect.DeepEqual(restored.Guesses(), game.Guesses()) || !reflect.DeepEqual(restored.Feedback(), game.Feedback()) {
+				t.Errorf("restored guesses = %v, want %v", restored.Guesses(), game.Guesses())
+			}
+
+			for _, letter := range "crantelsbf" {
+				gotState, gotOK := restored.LetterState(letter)
+				wantState, wantOK := game.LetterState(letter)
+				if gotState != wantState || gotOK != wantOK {
+					t.Errorf("LetterState(%c) = %v %t, want %v %t", letter, gotState, gotOK, wantState, wantOK)
+				}
+			}
+		})
+	}
+}
+
+func TestUnmarshalRejectsInvalidGuesses(t *testing.T) {
+	var game Game
+	err := json.Unmarshal([]byte(`{"solution":"crane","guesses":["zzzzz"]}`), &game)
+	if !errors.Is(err, ErrNotInWordList) {
+		t.Errorf("Unmarshal() error = %v, want %v", err, ErrNotInWordList)
+	}
+}
+
+func TestShareText(t *testing.T) {
+	game, err := Restore("crane", false, []string{"slate", "crane"})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	result := game.Result()
+	tests := []struct {
+		name string
+		got  string
+		want string
+	}{
+		{"daily", result.ShareText(1234, false), "Wordle 1,234 2/6\n\n" + result.Grid()},
+		{"daily ascii", result.ShareText(987, true), "Wordle 987 2/6\n\n" + result.ASCIIGrid()},
+		{"practice", result.PracticeShareText(false), "Wordle practice 2/6\n\n" + result.Grid()},
+		{"practice ascii", result.PracticeShareText(true), "Wordle practice 2/6\n\n" + result.ASCIIGrid()},
+	}
+
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s share = %q, want %q", tt.name, tt.got, tt.want)
+		}
+	}
+}
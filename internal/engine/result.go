@@ -0,0 +1,223 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// Result is the compact summary of a game as stored with the stats
+type Result struct {
+	Won      bool     `json:"w"`           // Won
+	HardMode bool     `json:"h,omitempty"` // Hard mode
+	Guesses  []string `json:"g,omitempty"` // Guesses as compact strings: "LetterState" (c=correct, p=present, a=absent)
+}
+
+// Result returns the compact summary of the game
+func (g *Game) Result() Result {
+	result := Result{
+		Won:      g.state == StateWon,
+		HardMode: g.hardMode,
+		Guesses:  []string{},
+	}
+
+	// Each guess is encoded as "L1S1L2S2L3S3L4S4L5S5"
+	for _, feedback := range g.feedback {
+		var guess strings.Builder
+		for _, letter := range feedback {
+			guess.WriteRune(unicode.ToUpper(letter.Letter))
+			guess.WriteByte(stateCodes[letter.State])
+		}
+
+		result.Guesses = append(result.Guesses, guess.String())
+	}
+
+	return result
+}
+
+// stateCodes maps letter states to their compact encoding
+var stateCodes = map[LetterState]byte{
+	LetterCorrect: 'c',
+	LetterPresent: 'p',
+	LetterAbsent:  'a',
+}
+
+// JSON encodes the result for storage
+func (r Result) JSON() (string, error) {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal game result: %w", err)
+	}
+
+	return string(data), nil
+}
+
+// ParseResult decodes a stored game result
+func ParseResult(data string) (*Result, error) {
+	var result Result
+	if err := json.Unmarshal([]byte(data), &result); err != nil {
+		return nil, fmt.Errorf("failed to parse game result: %w", err)
+	}
+
+	return &result, nil
+}
+
+// Feedback decodes the compact guesses into per-letter feedback
+func (r Result) Feedback() []Feedback {
+	feedback := make([]Feedback, 0, len(r.Guesses))
+
+	for _, guess := range r.Guesses {
+		letters := []rune(guess)
+		row := make(Feedback, 0, len(letters)/2)
+
+		for i := 0; i+1 < len(letters); i += 2 {
+			state := LetterAbsent
+			switch letters[i+1] {
+			case 'c':
+				state = LetterCorrect
+			case 'p':
+				state = LetterPresent
+			}
+
+			row = append(row, LetterResult{Letter: unicode.ToLower(letters[i]), State: state})
+		}
+
+		feedback = append(feedback, row)
+	}
+
+	return feedback
+}
+
+// gridSquares maps letter states to the squares of a shareable grid
+var gridSquares = map[LetterState]string{
+	LetterCorrect: "🟩",
+	LetterPresent: "🟨",
+	LetterAbsent:  "⬛",
+}
+
+// asciiSquares maps letter states to plain characters for terminals that can't render emoji
+var asciiSquares = map[LetterState]string{
+	LetterCorrect: "G",
+	LetterPresent: "Y",
+	LetterAbsent:  ".",
+}
+
+// Score returns the score of the result, e.g. "3/6", "X/6" for a loss and "3/6*" in hard mode
+func (r Result) Score() string {
+	score := "X"
+	if r.Won {
+		score = fmt.Sprintf("%d", len(r.Guesses))
+	}
+
+	score += fmt.Sprintf("/%d", MaxGuesses)
+	if r.HardMode {
+		score += "*"
+	}
+
+	return score
+}
+
+// Grid renders the result as colored squares without revealing any letters
+func (r Result) Grid() string {
+	return r.grid(gridSquares)
+}
+
+// ASCIIGrid renders the result like Grid, using G for correct, Y for present and . for absent letters
+func (r Result) ASCIIGrid() string {
+	return r.grid(asciiSquares)
+}
+
+func (r Result) grid(squares map[LetterState]string) string {
+	var rows []string
+	for _, feedback := range r.Feedback() {
+		var row strings.Builder
+		for _, letter := range feedback {
+			row.WriteString(squares[letter.State])
+		}
+
+		rows = append(rows, row.String())
+	}
+
+	return strings.Join(rows, "\n")
+}
+
+// ShareText returns the result in the standard shareable format, e.g.
+//
+//	Wordle 1,234 4/6
+//
+//	⬛🟨⬛⬛⬛
+//	...
+//
+// Plain characters are used instead of emoji if ascii is set.
+func (r Result) ShareText(puzzleNumber int, ascii bool) string {
+	return r.shareText(formatThousands(puzzleNumber), ascii)
+}
+
+// PracticeShareText returns the result of a practice game in the shareable
+// format, labeled "Wordle practice" as it has no puzzle number
+func (r Result) PracticeShareText(ascii bool) string {
+	return r.shareText("practice", ascii)
+}
+
+// shareText returns the shareable result with the label after "Wordle"
+func (r Result) shareText(label string, ascii bool) string {
+	grid := r.Grid()
+	if ascii {
+		grid = r.ASCIIGrid()
+	}
+
+	return fmt.Sprintf("Wordle %s %s\n\n%s", label, r.Score(), grid)
+}
+
+// formatThousands formats a number with comma thousands separators
+func formatThousands(n int) string {
+	if n < 0 {
+		return "-" + formatThousands(-n)
+	}
+
+	digits := fmt.Sprintf("%d", n)
+
+	var s strings.Builder
+	for i, digit := range digits {
+		if i > 0 && (len(digits)-i)%3 == 0 {
+			s.WriteByte(',')
+		}
+
+		s.WriteRune(digit)
+	}
+
+	return s.String()
+}
+
+// snapshot is the serialized form of an in-progress or finished game
+type snapshot struct {
+	Solution string   `json:"solution"`
+	HardMode bool     `json:"hard_mode,omitempty"`
+	Guesses  []string `json:"guesses"`
+}
+
+// MarshalJSON serializes the game so it can be restored later
+func (g *Game) MarshalJSON() ([]byte, error) {
+	return json.Marshal(snapshot{
+		Solution: g.solution,
+		HardMode: g.hardMode,
+		Guesses:  g.Guesses(),
+	})
+}
+
+// UnmarshalJSON restores a serialized game by replaying its guesses
+func (g *Game) UnmarshalJSON(data []byte) error {
+	var snap snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return err
+	}
+
+	restored, err := Restore(snap.Solution, snap.HardMode, snap.Guesses)
+	if err != nil {
+		return err
+	}
+
+	*g = *restored
+	return nil
+}
@@ -0,0 +1,144 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "wordle_ssh"
+
+// registry holds the metrics of this package, plus the Go runtime and process metrics
+var registry = prometheus.NewRegistry()
+
+var (
+	// SSHConnections counts authentication attempts by result (accepted, blocked
+	// by an access rule or not_allowed in allowlist mode)
+	SSHConnections = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ssh_connections_total",
+		Help:      "SSH connections by authentication result.",
+	}, []string{"result"}))
+
+	// RateLimited counts rejected connections and sessions by reason (ip_rate,
+	// key_rate, ip_sessions, key_sessions or banned)
+	RateLimited = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "rate_limited_total",
+		Help:      "Connections and sessions rejected by the rate limiter by reason.",
+	}, []string{"reason"}))
+
+	// Bans counts IPs temporarily banned after repeated authentication failures
+	Bans = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "bans_total",
+		Help:      "IPs temporarily banned after repeated authentication failures.",
+	}))
+
+	// BannedIPs is the number of currently banned IPs
+	BannedIPs = register(prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "banned_ips",
+		Help:      "Number of currently banned IPs.",
+	}))
+
+	// ActiveSessions is the number of open SSH sessions
+	ActiveSessions = register(prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "active_sessions",
+		Help:      "Number of open SSH sessions.",
+	}))
+
+	// GamesStarted counts new games by mode, resumed games are not counted again
+	GamesStarted = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_started_total",
+		Help:      "Games started by mode.",
+	}, []string{"mode"}))
+
+	// GamesFinished counts recorded games by mode and result (won or lost)
+	GamesFinished = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "games_finished_total",
+		Help:      "Games recorded by mode and result.",
+	}, []string{"mode", "result"}))
+
+	// InvalidGuesses counts rejected guesses by reason
+	InvalidGuesses = register(prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "invalid_guesses_total",
+		Help:      "Rejected guesses by reason.",
+	}, []string{"reason"}))
+
+	// WordFetchFailures counts failed fetches of a daily word from the word sources
+	WordFetchFailures = register(prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "word_fetch_failures_total",
+		Help:      "Failed fetches of a daily word from the word sources.",
+	}))
+
+	// WordFetchDuration observes fetches of a daily word from the word sources
+	WordFetchDuration = register(prometheus.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "word_fetch_duration_seconds",
+		Help:      "Duration of daily word fetches from the word sources.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30},
+	}))
+
+	// DBQueryDuration observes stats store operations by name
+	DBQueryDuration = register(prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "db_query_duration_seconds",
+		Help:      "Duration of stats database operations.",
+		Buckets:   []float64{0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 1},
+	}, []string{"operation"}))
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// register adds a collector to the registry and returns it
+func register[C prometheus.Collector](collector C) C {
+	registry.MustRegister(collector)
+	return collector
+}
+
+// Handler serves the metrics in the Prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveQuery starts timing a stats database operation, call the returned
+// function when it is done
+func ObserveQuery(operation string) func() {
+	start := time.Now()
+	return func() {
+		DBQueryDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	}
+}
+
+// InvalidGuess counts a guess rejected by the game engine
+func InvalidGuess(err error) {
+	var hardModeErr *engine.HardModeError
+
+	reason := "other"
+	switch {
+	case errors.Is(err, engine.ErrWrongLength):
+		reason = "wrong_length"
+	case errors.Is(err, engine.ErrNotInWordList):
+		reason = "not_in_word_list"
+	case errors.As(err, &hardModeErr):
+		reason = "hard_mode"
+	}
+
+	InvalidGuesses.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+// accessRulesTTL is how long access rules are cached, changes made with the
+// CLI take effect within this time
+const accessRulesTTL = 10 * time.Second
+
+// blockedKeyContextKey marks a connection whose SSH key was blocked, so it is
+// not admitted as a guest when the client falls back to keyboard-interactive
+type blockedKeyContextKey struct{}
+
+// accessControl checks connections against the access rules in the database
+type accessControl struct {
+	store         *stats.Store
+	allowlistOnly bool
+	logger        *log.Logger
+
+	mu       sync.Mutex
+	rules    *stats.AccessRules
+	loadedAt time.Time
+}
+
+// newAccessControl creates an access control for the rules in the store
+func newAccessControl(store *stats.Store, allowlistOnly bool, logger *log.Logger) *accessControl {
+	return &accessControl{
+		store:         store,
+		allowlistOnly: allowlistOnly,
+		logger:        logger,
+	}
+}
+
+// Allow reports whether a connection may authenticate with the given key fingerprint
+func (a *accessControl) Allow(ctx ssh.Context, sshKeyFingerprint string) bool {
+	rules, err := a.load()
+	if err != nil {
+		// Without rules a private server stays closed, a public one stays open
+		a.logger.Error("Failed to load access rules", "error", err)
+		return !a.allowlistOnly
+	}
+
+	request := stats.AccessRequest{
+		Username:          ctx.User(),
+		SSHKeyFingerprint: sshKeyFingerprint,
+		IP:                remoteIP(ctx.RemoteAddr()),
+	}
+
+	allowed, rule := rules.Check(request, a.allowlistOnly)
+	if allowed {
+		metrics.SSHConnections.WithLabelValues("accepted").Inc()
+		return true
+	}
+
+	if rule == nil {
+		a.logger.Info("Blocked connection not on the allowlist", "username", request.Username, "address", ctx.RemoteAddr(), "client", ctx.ClientVersion())
+		metrics.SSHConnections.WithLabelValues("not_allowed").Inc()
+		return false
+	}
+
+	if rule.Kind == stats.RuleKey {
+		ctx.SetValue(blockedKeyContextKey{}, true)
+	}
+
+	a.logger.Info("Blocked connection",
+		"username", request.Username,
+		"address", ctx.RemoteAddr(),
+		"client", ctx.ClientVersion(),
+		"rule", rule.ID,
+		"kind", rule.Kind,
+		"reason", rule.Reason,
+	)
+	metrics.SSHConnections.WithLabelValues("blocked").Inc()
+	return false
+}
+
+// AllowGuest reports whether a connection may authenticate as a guest without
+// a key. Username and address rules apply as for any connection, a guest has
+// no key to block, but a connection whose key was just blocked is refused.
+func (a *accessControl) AllowGuest(ctx ssh.Context) bool {
+	if blocked, _ := ctx.Value(blockedKeyContextKey{}).(bool); blocked {
+		a.logger.Info("Blocked guest connection with a blocked key", "username", ctx.User(), "address", ctx.RemoteAddr(), "client", ctx.ClientVersion())
+		metrics.SSHConnections.WithLabelValues("blocked").Inc()
+		return false
+	}
+
+	return a.Allow(ctx, "")
+}
+
+// load returns the cached access rules, reloading them once they are stale
+func (a *accessControl) load() (*stats.AccessRules, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.rules != nil && time.Since(a.loadedAt) < accessRulesTTL {
+		return a.rules, nil
+	}
+
+	rules, err := a.store.LoadAccessRules()
+	if err != nil {
+		return nil, err
+	}
+
+	a.rules = rules
+	a.loadedAt = time.Now()
+	return rules, nil
+}
+
+// remoteIP returns the IP address of a remote address, or nil if it has none
+func remoteIP(addr net.Addr) net.IP {
+	if tcpAddr, ok := addr.(*net.TCPAddr); ok {
+		return tcpAddr.IP
+	}
+
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return nil
+	}
+
+	return net.ParseIP(host)
+}
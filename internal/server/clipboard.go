@@ -0,0 +1,40 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/muesli/termenv"
+)
+
+// newClipboard returns a clipboard that copies to the client's terminal with OSC52
+func newClipboard(sess ssh.Session) *termenv.Output {
+	return termenv.NewOutput(sess, termenv.WithEnvironment(sessionEnviron{sess}))
+}
+
+// sessionEnviron exposes the environment of the client's terminal, not the server's
+type sessionEnviron struct {
+	sess ssh.Session
+}
+
+// Environ returns the environment variables sent by the client
+func (e sessionEnviron) Environ() []string {
+	return e.sess.Environ()
+}
+
+// Getenv returns an environment variable sent by the client, TERM comes from the PTY
+func (e sessionEnviron) Getenv(key string) string {
+	if key == "TERM" {
+		if pty, _, ok := e.sess.Pty(); ok {
+			return pty.Term
+		}
+	}
+
+	for _, env := range e.sess.Environ() {
+		if name, value, ok := strings.Cut(env, "="); ok && name == key {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,153 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// errUsage is returned by a command whose arguments could not be parsed,
+// the flag set has already printed the problem and the usage
+var errUsage = errors.New("invalid usage")
+
+// command is a non-interactive subcommand, run with "ssh <host> <name> [args]"
+type command struct {
+	name        string
+	usage       string
+	description string
+	// guests is whether the command can be run without an SSH key
+	guests bool
+
+	// run executes the command, a returned error is printed to stderr
+	run func(sess ssh.Session, args []string) error
+}
+
+// commands returns every subcommand in the order they are listed by help
+func (s *Server) commands() []command {
+	return []command{
+		{name: "play", usage: "play --plain [--json]", description: "Play today's puzzle sending one guess per line, without a terminal", run: s.runPlay},
+		{name: "stats", usage: "stats [--json]", description: "Show your statistics", run: s.runStats},
+		{name: "history", usage: "history [--limit n] [--json]", description: "List your finished games, newest first", run: s.runHistory},
+		{name: "share", usage: "share [date] [--ascii] [--json]", description: "Print the shareable result of today's or a past puzzle", run: s.runShare},
+		{name: "leaderboard", usage: "leaderboard [today|win-rate|streak|max-streak] [--limit n] [--json]", description: "Show a leaderboard", run: s.runLeaderboard},
+		{name: "join", usage: "join <invite-code>", description: "Join a group with its invite code", run: s.runJoin},
+		{name: "help", usage: "help", description: "List the available commands", guests: true, run: s.runHelp},
+	}
+}
+
+// commandMiddleware runs subcommands without starting the TUI, so they work
+// without a terminal and can be piped into other tools
+func (s *Server) commandMiddleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			args := sess.Command()
+			if len(args) == 0 {
+				next(sess)
+				return
+			}
+
+			for _, cmd := range s.commands() {
+				if cmd.name != args[0] {
+					continue
+				}
+
+				if !cmd.guests && isGuest(sess) {
+					wish.Fatalln(sess, "Error: commands need an SSH key, guests can only practice in the terminal.")
+					return
+				}
+
+				s.config.Logger.Debug("Running command", "command", cmd.name, "username", sess.User())
+
+				err := cmd.run(sess, args[1:])
+				if errors.Is(err, errUsage) {
+					_ = sess.Exit(2)
+					return
+				}
+
+				if err != nil {
+					wish.Fatalln(sess, "Error:", err)
+				}
+
+				return
+			}
+
+			wish.Fatalf(sess, "Unknown command %q, run \"ssh <host> help\" to list the commands.\n", args[0])
+		}
+	}
+}
+
+// runHelp lists the available commands
+func (s *Server) runHelp(sess ssh.Session, args []string) error {
+	wish.Println(sess, "Usage: ssh <host> [command]")
+	wish.Println(sess, "")
+	wish.Println(sess, "Without a command the game starts in your terminal.")
+
+	// Guests only see the commands they can run
+	guest := isGuest(sess)
+	commands := make([]command, 0, len(s.commands()))
+	for _, cmd := range s.commands() {
+		if cmd.guests || !guest {
+			commands = append(commands, cmd)
+		}
+	}
+
+	if guest {
+		wish.Println(sess, "Guests without an SSH key can only practice there, connect with a key to")
+		wish.Println(sess, "keep stats and use the other commands.")
+	}
+	wish.Println(sess, "")
+	wish.Println(sess, "Commands:")
+
+	width := 0
+	for _, cmd := range commands {
+		width = max(width, len(cmd.usage))
+	}
+
+	for _, cmd := range commands {
+		wish.Printf(sess, "  %-*s  %s\n", width, cmd.usage, cmd.description)
+	}
+
+	return nil
+}
+
+// newFlagSet creates a flag set for a command that reports errors to the session
+func newFlagSet(sess ssh.Session, cmd string) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	fs.SetOutput(sess.Stderr())
+	return fs
+}
+
+// parseArgs parses flags placed anywhere between the positional arguments and
+// returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, errUsage
+		}
+
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// writeJSON writes a value to the session as indented JSON
+func writeJSON(sess ssh.Session, v any) error {
+	encoder := json.NewEncoder(sess)
+	encoder.SetIndent("", "  ")
+
+	if err := encoder.Encode(v); err != nil {
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+
+	return nil
+}
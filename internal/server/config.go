@@ -0,0 +1,486 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/charmbracelet/log"
+	"github.com/f-gillmann/wordle-ssh/internal/wordle"
+)
+
+const (
+	defaultPort        = "23234"
+	defaultHostKeyPath = ".ssh/id_ed25519"
+	defaultDBPath      = "./wordle-stats.db"
+	defaultMOTD        = "Welcome to Wordle SSH!"
+	defaultWordSources = "nyt"
+	defaultNYTTimeout  = 10 * time.Second
+
+	defaultShutdownTimeout = 30 * time.Second
+	defaultIdleTimeout     = 15 * time.Minute
+	defaultIdleWarning     = time.Minute
+	defaultMaxSession      = 2 * time.Hour
+
+	defaultLeaderboardMinGames = 10
+)
+
+// defaultRateLimit is used when the rate limits are not configured
+var defaultRateLimit = RateLimitConfig{
+	IPPerMinute:       30,
+	KeyPerMinute:      20,
+	Burst:             10,
+	MaxSessionsPerIP:  10,
+	MaxSessionsPerKey: 3,
+	BanAfterFailures:  10,
+	BanWindow:         10 * time.Minute,
+	BanDuration:       time.Hour,
+}
+
+// Config holds the server configuration
+type Config struct {
+	Host        string
+	Port        string
+	HostKeyPath string
+	DBPath      string
+	MOTD        string
+	Logger      *log.Logger
+	LogLevel    log.Level
+
+	// ShutdownTimeout is how long open sessions may finish their games on shutdown
+	ShutdownTimeout time.Duration
+	// IdleTimeout disconnects sessions without input for this long, disabled when zero
+	IdleTimeout time.Duration
+	// IdleWarning is how long before a disconnect the session is warned
+	IdleWarning time.Duration
+	// MaxSessionDuration disconnects sessions open for this long, disabled when zero
+	MaxSessionDuration time.Duration
+
+	// WordSources lists the word sources to try in order ("nyt", "schedule")
+	WordSources []string
+	// SchedulePath is the schedule file used by the "schedule" word source
+	SchedulePath string
+	// NYTTimeout is the timeout of requests to the NYTimes Wordle API
+	NYTTimeout time.Duration
+	// WordSource overrides WordSources when set
+	WordSource wordle.WordSource
+
+	// HTTPAddr is the address of the HTTP listener serving /metrics, /healthz and
+	// /readyz, disabled when empty
+	HTTPAddr string
+
+	// AllowlistOnly admits only connections matching an allow rule
+	AllowlistOnly bool
+	// AllowGuests admits users without an SSH key to practice games that are not recorded
+	AllowGuests bool
+	// RateLimit limits how often and how many sessions a client may open
+	RateLimit RateLimitConfig
+
+	// LeaderboardOptIn hides users from leaderboards until they opt in
+	LeaderboardOptIn bool
+	// LeaderboardMinGames is the number of games needed to be ranked by win rate
+	LeaderboardMinGames int
+}
+
+// RateLimitConfig limits connections per client, a zero value disables a limit
+type RateLimitConfig struct {
+	// IPPerMinute and KeyPerMinute are the sustained connection rates per
+	// remote IP and per SSH key, Burst is how many connections may come at once
+	IPPerMinute  int `toml:"ip_per_minute"`
+	KeyPerMinute int `toml:"key_per_minute"`
+	Burst        int `toml:"burst"`
+
+	// MaxSessionsPerIP and MaxSessionsPerKey limit concurrent sessions
+	MaxSessionsPerIP  int `toml:"max_sessions_per_ip"`
+	MaxSessionsPerKey int `toml:"max_sessions_per_key"`
+
+	// BanAfterFailures authentication failures of an IP within BanWindow
+	// ban it for BanDuration
+	BanAfterFailures int           `toml:"ban_after_failures"`
+	BanWindow        time.Duration `toml:"ban_window"`
+	BanDuration      time.Duration `toml:"ban_duration"`
+}
+
+// fileConfig is the layout of the configuration file
+type fileConfig struct {
+	Host        string `toml:"host"`
+	Port        int    `toml:"port"`
+	HostKeyPath string `toml:"host_key_path"`
+	DBPath      string `toml:"db_path"`
+	MOTD        string `toml:"motd"`
+	LogLevel    string `toml:"log_level"`
+
+	ShutdownTimeout time.Duration `toml:"shutdown_timeout"`
+
+	Words       wordsFileConfig       `toml:"words"`
+	HTTP        httpFileConfig        `toml:"http"`
+	Session     sessionFileConfig     `toml:"session"`
+	Access      accessFileConfig      `toml:"access"`
+	RateLimit   RateLimitConfig       `toml:"rate_limit"`
+	Leaderboard leaderboardFileConfig `toml:"leaderboard"`
+}
+
+type wordsFileConfig struct {
+	Sources      []string      `toml:"sources"`
+	SchedulePath string        `toml:"schedule_path"`
+	NYTTimeout   time.Duration `toml:"nyt_timeout"`
+}
+
+type httpFileConfig struct {
+	Addr string `toml:"addr"`
+}
+
+type sessionFileConfig struct {
+	IdleTimeout time.Duration `toml:"idle_timeout"`
+	IdleWarning time.Duration `toml:"idle_warning"`
+	MaxDuration time.Duration `toml:"max_duration"`
+}
+
+type accessFileConfig struct {
+	AllowlistOnly bool `toml:"allowlist_only"`
+	AllowGuests   bool `toml:"allow_guests"`
+}
+
+type leaderboardFileConfig struct {
+	OptIn    bool `toml:"opt_in"`
+	MinGames int  `toml:"min_games"`
+}
+
+// logLevels maps the accepted log level names to their level
+var logLevels = map[string]log.Level{
+	"debug": log.DebugLevel,
+	"info":  log.InfoLevel,
+	"warn":  log.WarnLevel,
+	"error": log.ErrorLevel,
+}
+
+// defaultFileConfig returns the configuration used when nothing is set
+func defaultFileConfig() fileConfig {
+	port, _ := strconv.Atoi(defaultPort)
+
+	return fileConfig{
+		Port:        port,
+		HostKeyPath: defaultHostKeyPath,
+		DBPath:      defaultDBPath,
+		MOTD:        defaultMOTD,
+		LogLevel:    "info",
+
+		ShutdownTimeout: defaultShutdownTimeout,
+
+		Words: wordsFileConfig{
+			Sources:    splitList(defaultWordSources),
+			NYTTimeout: defaultNYTTimeout,
+		},
+		Session: sessionFileConfig{
+			IdleTimeout: defaultIdleTimeout,
+			IdleWarning: defaultIdleWarning,
+			MaxDuration: defaultMaxSession,
+		},
+		RateLimit: defaultRateLimit,
+		Leaderboard: leaderboardFileConfig{
+			MinGames: defaultLeaderboardMinGames,
+		},
+	}
+}
+
+// LoadConfig loads the configuration from a TOML file, if a path is given or
+// set in WORDLE_SSH_CONFIG, with environment variables overriding the file.
+// All invalid options are reported together.
+func LoadConfig(path string) (Config, error) {
+	file := defaultFileConfig()
+
+	if path == "" {
+		path = os.Getenv("WORDLE_SSH_CONFIG")
+	}
+
+	if path != "" {
+		meta, err := toml.DecodeFile(path, &file)
+		if err != nil {
+			return Config{}, fmt.Errorf("failed to read config file %s: %w", path, err)
+		}
+
+		if undecoded := meta.Undecoded(); len(undecoded) > 0 {
+			keys := make([]string, 0, len(undecoded))
+			for _, key := range undecoded {
+				keys = append(keys, key.String())
+			}
+
+			return Config{}, fmt.Errorf("unknown option(s) in config file %s: %s", path, strings.Join(keys, ", "))
+		}
+	}
+
+	errs := file.applyEnv()
+	errs = append(errs, file.validate()...)
+	if len(errs) > 0 {
+		return Config{}, fmt.Errorf("invalid configuration:\n%w", errors.Join(errs...))
+	}
+
+	return file.config(), nil
+}
+
+// applyEnv overrides the file with the environment variables that are set
+func (file *fileConfig) applyEnv() []error {
+	var errs []error
+
+	setString := func(name string, target *string) {
+		if value := os.Getenv(name); value != "" {
+			*target = value
+		}
+	}
+
+	setInt := func(name string, target *int) {
+		if value := os.Getenv(name); value != "" {
+			if n, err := strconv.Atoi(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a number", name, value))
+			} else {
+				*target = n
+			}
+		}
+	}
+
+	setBool := func(name string, target *bool) {
+		if value := os.Getenv(name); value != "" {
+			if b, err := strconv.ParseBool(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not true or false", name, value))
+			} else {
+				*target = b
+			}
+		}
+	}
+
+	setDuration := func(name string, target *time.Duration) {
+		if value := os.Getenv(name); value != "" {
+			if d, err := time.ParseDuration(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %q is not a duration like 30s", name, value))
+			} else {
+				*target = d
+			}
+		}
+	}
+
+	setString("WORDLE_SSH_HOST", &file.Host)
+	setInt("WORDLE_SSH_PORT", &file.Port)
+	setString("WORDLE_SSH_HOST_KEY_PATH", &file.HostKeyPath)
+	setString("WORDLE_SSH_DB_PATH", &file.DBPath)
+	setString("WORDLE_SSH_MOTD", &file.MOTD)
+	setString("WORDLE_SSH_LOG_LEVEL", &file.LogLevel)
+	setDuration("WORDLE_SSH_SHUTDOWN_TIMEOUT", &file.ShutdownTimeout)
+
+	if value := os.Getenv("WORDLE_SSH_WORD_SOURCES"); value != "" {
+		file.Words.Sources = splitList(value)
+	}
+
+	setString("WORDLE_SSH_SCHEDULE_PATH", &file.Words.SchedulePath)
+	setDuration("WORDLE_SSH_NYT_TIMEOUT", &file.Words.NYTTimeout)
+	setString("WORDLE_SSH_HTTP_ADDR", &file.HTTP.Addr)
+	setDuration("WORDLE_SSH_IDLE_TIMEOUT", &file.Session.IdleTimeout)
+	setDuration("WORDLE_SSH_IDLE_WARNING", &file.Session.IdleWarning)
+	setDuration("WORDLE_SSH_MAX_SESSION_DURATION", &file.Session.MaxDuration)
+	setBool("WORDLE_SSH_ALLOWLIST_ONLY", &file.Access.AllowlistOnly)
+	setBool("WORDLE_SSH_ALLOW_GUESTS", &file.Access.AllowGuests)
+
+	setInt("WORDLE_SSH_RATE_LIMIT_IP_PER_MINUTE", &file.RateLimit.IPPerMinute)
+	setInt("WORDLE_SSH_RATE_LIMIT_KEY_PER_MINUTE", &file.RateLimit.KeyPerMinute)
+	setInt("WORDLE_SSH_RATE_LIMIT_BURST", &file.RateLimit.Burst)
+	setInt("WORDLE_SSH_MAX_SESSIONS_PER_IP", &file.RateLimit.MaxSessionsPerIP)
+	setInt("WORDLE_SSH_MAX_SESSIONS_PER_KEY", &file.RateLimit.MaxSessionsPerKey)
+	setInt("WORDLE_SSH_BAN_AFTER_FAILURES", &file.RateLimit.BanAfterFailures)
+	setDuration("WORDLE_SSH_BAN_WINDOW", &file.RateLimit.BanWindow)
+	setDuration("WORDLE_SSH_BAN_DURATION", &file.RateLimit.BanDuration)
+
+	setBool("WORDLE_SSH_LEADERBOARD_OPT_IN", &file.Leaderboard.OptIn)
+	setInt("WORDLE_SSH_LEADERBOARD_MIN_GAMES", &file.Leaderboard.MinGames)
+
+	return errs
+}
+
+// validate checks every option and returns an error for each invalid one
+func (file *fileConfig) validate() []error {
+	var errs []error
+
+	if file.Port < 1 || file.Port > 65535 {
+		errs = append(errs, fmt.Errorf("port: %d is not between 1 and 65535", file.Port))
+	}
+
+	if file.HostKeyPath == "" {
+		errs = append(errs, errors.New("host_key_path: must not be empty"))
+	}
+
+	if file.DBPath == "" {
+		errs = append(errs, errors.New("db_path: must not be empty"))
+	}
+
+	if _, ok := logLevels[strings.ToLower(file.LogLevel)]; !ok {
+		errs = append(errs, fmt.Errorf("log_level: unknown level %q, expected debug, info, warn or error", file.LogLevel))
+	}
+
+	if file.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("shutdown_timeout: %s must be positive", file.ShutdownTimeout))
+	}
+
+	if len(file.Words.Sources) == 0 {
+		errs = append(errs, errors.New("words.sources: at least one word source is required"))
+	}
+
+	for _, source := range file.Words.Sources {
+		switch strings.ToLower(source) {
+		case "nyt":
+		case "schedule":
+			if file.Words.SchedulePath == "" {
+				errs = append(errs, errors.New("words.schedule_path: required by the schedule word source"))
+			} else if _, err := os.Stat(file.Words.SchedulePath); err != nil {
+				errs = append(errs, fmt.Errorf("words.schedule_path: %w", err))
+			}
+		default:
+			errs = append(errs, fmt.Errorf("words.sources: unknown word source %q, expected nyt or schedule", source))
+		}
+	}
+
+	if file.Words.NYTTimeout < 0 {
+		errs = append(errs, fmt.Errorf("words.nyt_timeout: %s must not be negative", file.Words.NYTTimeout))
+	}
+
+	if file.HTTP.Addr != "" {
+		if _, _, err := net.SplitHostPort(file.HTTP.Addr); err != nil {
+			errs = append(errs, fmt.Errorf("http.addr: %w", err))
+		}
+	}
+
+	if file.Session.IdleTimeout < 0 || file.Session.MaxDuration < 0 {
+		errs = append(errs, errors.New("session.idle_timeout and session.max_duration: must not be negative, 0 disables them"))
+	}
+
+	if file.Session.IdleWarning < 0 {
+		errs = append(errs, fmt.Errorf("session.idle_warning: %s must not be negative", file.Session.IdleWarning))
+	}
+
+	if file.Session.IdleTimeout > 0 && file.Session.IdleWarning >= file.Session.IdleTimeout {
+		errs = append(errs, fmt.Errorf("session.idle_warning: %s must be shorter than session.idle_timeout %s", file.Session.IdleWarning, file.Session.IdleTimeout))
+	}
+
+	if file.Session.MaxDuration > 0 && file.Session.IdleWarning >= file.Session.MaxDuration {
+		errs = append(errs, fmt.Errorf("session.idle_warning: %s must be shorter than session.max_duration %s", file.Session.IdleWarning, file.Session.MaxDuration))
+	}
+
+	limits := map[string]int{
+		"rate_limit.ip_per_minute":        file.RateLimit.IPPerMinute,
+		"rate_limit.key_per_minute":       file.RateLimit.KeyPerMinute,
+		"rate_limit.burst":                file.RateLimit.Burst,
+		"rate_limit.max_sessions_per_ip":  file.RateLimit.MaxSessionsPerIP,
+		"rate_limit.max_sessions_per_key": file.RateLimit.MaxSessionsPerKey,
+		"rate_limit.ban_after_failures":   file.RateLimit.BanAfterFailures,
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(limits)) {
+		if limits[name] < 0 {
+			errs = append(errs, fmt.Errorf("%s: %d must not be negative, 0 disables the limit", name, limits[name]))
+		}
+	}
+
+	if (file.RateLimit.IPPerMinute > 0 || file.RateLimit.KeyPerMinute > 0) && file.RateLimit.Burst < 1 {
+		errs = append(errs, errors.New("rate_limit.burst: must be at least 1 when a connection rate is set"))
+	}
+
+	if file.RateLimit.BanAfterFailures > 0 && (file.RateLimit.BanWindow <= 0 || file.RateLimit.BanDuration <= 0) {
+		errs = append(errs, errors.New("rate_limit.ban_window and rate_limit.ban_duration: must be positive when ban_after_failures is set"))
+	}
+
+	if file.Leaderboard.MinGames < 1 {
+		errs = append(errs, fmt.Errorf("leaderboard.min_games: %d must be at least 1", file.Leaderboard.MinGames))
+	}
+
+	return errs
+}
+
+// config converts a validated file configuration
+func (file *fileConfig) config() Config {
+	return Config{
+		Host:        file.Host,
+		Port:        strconv.Itoa(file.Port),
+		HostKeyPath: file.HostKeyPath,
+		DBPath:      file.DBPath,
+		MOTD:        file.MOTD,
+		LogLevel:    logLevels[strings.ToLower(file.LogLevel)],
+
+		ShutdownTimeout: file.ShutdownTimeout,
+
+		WordSources:  file.Words.Sources,
+		SchedulePath: file.Words.SchedulePath,
+		NYTTimeout:   file.Words.NYTTimeout,
+		HTTPAddr:     file.HTTP.Addr,
+
+		IdleTimeout:        file.Session.IdleTimeout,
+		IdleWarning:        file.Session.IdleWarning,
+		MaxSessionDuration: file.Session.MaxDuration,
+
+		AllowlistOnly: file.Access.AllowlistOnly,
+		AllowGuests:   file.Access.AllowGuests,
+		RateLimit:     file.RateLimit,
+
+		LeaderboardOptIn:    file.Leaderboard.OptIn,
+		LeaderboardMinGames: file.Leaderboard.MinGames,
+	}
+}
+
+// WriteTOML writes the configuration in the format of the configuration file
+func (config Config) WriteTOML(w io.Writer) error {
+	port, _ := strconv.Atoi(config.Port)
+
+	file := fileConfig{
+		Host:        config.Host,
+		Port:        port,
+		HostKeyPath: config.HostKeyPath,
+		DBPath:      config.DBPath,
+		MOTD:        config.MOTD,
+		LogLevel:    config.LogLevel.String(),
+
+		ShutdownTimeout: config.ShutdownTimeout,
+
+		Words: wordsFileConfig{
+			Sources:      config.WordSources,
+			SchedulePath: config.SchedulePath,
+			NYTTimeout:   config.NYTTimeout,
+		},
+		HTTP: httpFileConfig{
+			Addr: config.HTTPAddr,
+		},
+		Session: sessionFileConfig{
+			IdleTimeout: config.IdleTimeout,
+			IdleWarning: config.IdleWarning,
+			MaxDuration: config.MaxSessionDuration,
+		},
+		Access: accessFileConfig{
+			AllowlistOnly: config.AllowlistOnly,
+			AllowGuests:   config.AllowGuests,
+		},
+		RateLimit: config.RateLimit,
+		Leaderboard: leaderboardFileConfig{
+			OptIn:    config.LeaderboardOptIn,
+			MinGames: config.LeaderboardMinGames,
+		},
+	}
+
+	encoder := toml.NewEncoder(w)
+	encoder.Indent = ""
+	return encoder.Encode(file)
+}
+
+// splitList splits a comma separated list and drops empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// clearEnv unsets every WORDLE_SSH_ variable for the test, so the
+// environment of the test run can't change the loaded configuration
+func clearEnv(t *testing.T) {
+	t.Helper()
+
+	for _, variable := range os.Environ() {
+		name, _, _ := strings.Cut(variable, "=")
+		if strings.HasPrefix(name, "WORDLE_SSH_") {
+			t.Setenv(name, "")
+		}
+	}
+}
+
+// writeConfig writes a configuration file and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	return path
+}
+
+func TestLoadConfigDefaults(t *testing.T) {
+	clearEnv(t)
+
+	config, err := LoadConfig("")
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if config.Port != defaultPort || config.IdleTimeout != defaultIdleTimeout || config.LeaderboardMinGames != defaultLeaderboardMinGames {
+		t.Errorf("LoadConfig() = port %s idle %s min games %d, want the defaults", config.Port, config.IdleTimeout, config.LeaderboardMinGames)
+	}
+}
+
+func TestLoadConfigExampleFile(t *testing.T) {
+	clearEnv(t)
+
+	if _, err := LoadConfig("../../config.example.toml"); err != nil {
+		t.Errorf("LoadConfig() of the example config error = %v", err)
+	}
+}
+
+func TestLoadConfigEnvironmentOverridesFile(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+port = 2222
+motd = "from the file"
+
+[session]
+idle_timeout = "10m"
+idle_warning = "30s"
+`)
+
+	t.Setenv("WORDLE_SSH_PORT", "3333")
+	t.Setenv("WORDLE_SSH_IDLE_TIMEOUT", "20m")
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if config.Port != "3333" || config.IdleTimeout != 20*time.Minute {
+		t.Errorf("LoadConfig() = port %s idle timeout %s, want the environment 3333 and 20m", config.Port, config.IdleTimeout)
+	}
+
+	if config.MOTD != "from the file" || config.IdleWarning != 30*time.Second {
+		t.Errorf("LoadConfig() = motd %q idle warning %s, want the file values", config.MOTD, config.IdleWarning)
+	}
+
+	// The path may also come from the environment
+	t.Setenv("WORDLE_SSH_CONFIG", path)
+	if config, err := LoadConfig(""); err != nil || config.MOTD != "from the file" {
+		t.Errorf("LoadConfig() with WORDLE_SSH_CONFIG = motd %q, %v, want the file values", config.MOTD, err)
+	}
+}
+
+func TestLoadConfigRejectsUnknownKeys(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+port = 2222
+colour = "blue"
+
+[session]
+idle_timout = "10m"
+`)
+
+	_, err := LoadConfig(path)
+	if err == nil {
+		t.Fatal("LoadConfig() returned no error")
+	}
+
+	for _, key := range []string{"colour", "session.idle_timout"} {
+		if !strings.Contains(err.Error(), key) {
+			t.Errorf("LoadConfig() error = %q, want it to name %s", err, key)
+		}
+	}
+}
+
+func TestLoadConfigReportsEveryError(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		env  map[string]string
+		want []string
+	}{
+		{
+			name: "invalid environment values",
+			env:  map[string]string{"WORDLE_SSH_IDLE_TIMEOUT": "soon", "WORDLE_SSH_PORT": "ssh", "WORDLE_SSH_ALLOW_GUESTS": "maybe"},
+			want: []string{
+				`WORDLE_SSH_IDLE_TIMEOUT: "soon" is not a duration`,
+				`WORDLE_SSH_PORT: "ssh" is not a number`,
+				`WORDLE_SSH_ALLOW_GUESTS: "maybe" is not true or false`,
+			},
+		},
+		{
+			name: "idle warning as long as the idle timeout",
+			file: "[session]\nidle_timeout = \"1m\"\nidle_warning = \"1m\"\n",
+			want: []string{"session.idle_warning: 1m0s must be shorter than session.idle_timeout 1m0s"},
+		},
+		{
+			name: "idle warning longer than the maximum duration",
+			file: "[session]\nidle_timeout = \"0s\"\nidle_warning = \"5m\"\nmax_duration = \"2m\"\n",
+			want: []string{"session.idle_warning: 5m0s must be shorter than session.max_duration 2m0s"},
+		},
+		{
+			name: "errors of the file and the environment together",
+			file: "port = 70000\n\n[leaderboard]\nmin_games = 0\n",
+			env:  map[string]string{"WORDLE_SSH_NYT_TIMEOUT": "10"},
+			want: []string{
+				`WORDLE_SSH_NYT_TIMEOUT: "10" is not a duration`,
+				"port: 70000 is not between 1 and 65535",
+				"leaderboard.min_games: 0 must be at least 1",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clearEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+
+			path := ""
+			if tt.file != "" {
+				path = writeConfig(t, tt.file)
+			}
+
+			_, err := LoadConfig(path)
+			if err == nil {
+				t.Fatal("LoadConfig() returned no error")
+			}
+
+			for _, want := range tt.want {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("LoadConfig() error = %q, want it to contain %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestWriteTOMLRoundTrip(t *testing.T) {
+	clearEnv(t)
+	path := writeConfig(t, `
+port = 2222
+motd = "hello"
+
+[session]
+idle_timeout = "10m"
+idle_warning = "30s"
+max_duration = "1h"
+
+[access]
+allow_guests = true
+
+[rate_limit]
+ip_per_minute = 5
+burst = 2
+`)
+
+	config, err := LoadConfig(path)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := config.WriteTOML(&buf); err != nil {
+		t.Fatalf("WriteTOML() error = %v", err)
+	}
+
+	written, err := LoadConfig(writeConfig(t, buf.String()))
+	if err != nil {
+		t.Fatalf("LoadConfig() of the written config error = %v\n%s", err, buf.String())
+	}
+
+	if !reflect.DeepEqual(written, config) {
+		t.Errorf("written config = %+v, want %+v", written, config)
+	}
+}
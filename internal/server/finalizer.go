@@ -0,0 +1,76 @@
+package server
+
+import (
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+// finalizerInterval is how often abandoned games are looked for
+const finalizerInterval = time.Hour
+
+// gameFinalizer records daily games that were started but never finished as
+// losses once their puzzle date is over in every timezone
+type gameFinalizer struct {
+	store  *stats.Store
+	logger *log.Logger
+	now    func() time.Time
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newGameFinalizer creates a finalizer for the given store
+func newGameFinalizer(store *stats.Store, logger *log.Logger) *gameFinalizer {
+	return &gameFinalizer{
+		store:  store,
+		logger: logger,
+		now:    time.Now,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start finalizes abandoned games and starts the background goroutine
+func (f *gameFinalizer) Start() {
+	f.tick()
+
+	go func() {
+		defer close(f.done)
+
+		ticker := time.NewTicker(finalizerInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-f.stop:
+				return
+			case <-ticker.C:
+				f.tick()
+			}
+		}
+	}()
+}
+
+// Stop stops the background goroutine and waits for it to exit
+func (f *gameFinalizer) Stop() {
+	close(f.stop)
+	<-f.done
+}
+
+// tick finalizes the games of every date no longer in play anywhere
+func (f *gameFinalizer) tick() {
+	// The earliest timezone is the last one still playing a date
+	before := f.now().Add(earliestOffset).UTC().Format(stats.DateFormat)
+
+	count, err := f.store.FinalizeAbandonedGames(before)
+	if err != nil {
+		f.logger.Error("Failed to finalize abandoned games", "error", err)
+		return
+	}
+
+	if count > 0 {
+		f.logger.Info("Finalized abandoned games", "count", count, "before", before)
+	}
+}
@@ -0,0 +1,64 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+// healthCheckTimeout bounds the database ping of a health check
+const healthCheckTimeout = 2 * time.Second
+
+// newHTTPServer creates the optional HTTP listener serving /metrics, /healthz and /readyz
+func (s *Server) newHTTPServer() *http.Server {
+	mux := http.NewServeMux()
+	mux.Handle("GET /metrics", metrics.Handler())
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	mux.HandleFunc("GET /readyz", s.handleReady)
+
+	return &http.Server{
+		Addr:              s.config.HTTPAddr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+}
+
+// handleHealth reports whether the process is alive and the database can be reached
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), healthCheckTimeout)
+	defer cancel()
+
+	if err := s.statsStore.Ping(ctx); err != nil {
+		s.config.Logger.Warn("Health check failed", "error", err)
+		writeStatus(w, http.StatusServiceUnavailable, fmt.Sprintf("database unavailable: %v", err))
+		return
+	}
+
+	writeStatus(w, http.StatusOK, "ok")
+}
+
+// handleReady reports whether new players can be served: the SSH listener is
+// bound, today's word is loaded and the server is not shutting down
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case s.shuttingDown.Load():
+		writeStatus(w, http.StatusServiceUnavailable, "shutting down")
+	case !s.listening.Load():
+		writeStatus(w, http.StatusServiceUnavailable, "SSH listener not bound")
+	case !s.words.Loaded(time.Now().Format(stats.DateFormat)):
+		writeStatus(w, http.StatusServiceUnavailable, "today's word is not loaded")
+	default:
+		writeStatus(w, http.StatusOK, "ok")
+	}
+}
+
+// writeStatus writes a plain text response with a status code
+func writeStatus(w http.ResponseWriter, code int, message string) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(code)
+	_, _ = fmt.Fprintln(w, message)
+}
@@ -0,0 +1,31 @@
+package server
+
+import (
+	"errors"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+// runJoin handles "ssh <host> join <code>" without starting the TUI
+func (s *Server) runJoin(sess ssh.Session, args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: ssh <host> join <invite-code>")
+	}
+
+	username, sshKeyFingerprint := sessionIdentity(sess)
+
+	group, err := s.statsStore.JoinGroup(args[0], username, sshKeyFingerprint)
+	if errors.Is(err, stats.ErrGroupNotFound) {
+		return errors.New("no group found for this invite code")
+	}
+
+	if err != nil {
+		s.config.Logger.Error("Failed to join group", "error", err, "username", username)
+		return errors.New("failed to join the group, please try again later")
+	}
+
+	wish.Printf(sess, "You joined %s (%d members). Open Groups in the menu to see its leaderboards.\n", group.Name, group.MemberCount)
+	return nil
+}
@@ -0,0 +1,19 @@
+package server
+
+import (
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+)
+
+// sessionMetricsMiddleware tracks the number of open sessions
+func sessionMetricsMiddleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			metrics.ActiveSessions.Inc()
+			defer metrics.ActiveSessions.Dec()
+
+			next(sess)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+// feedbackWords maps letter states to the words of the plain protocol
+var feedbackWords = map[engine.LetterState]string{
+	engine.LetterCorrect: "GREEN",
+	engine.LetterPresent: "YELLOW",
+	engine.LetterAbsent:  "GRAY",
+}
+
+// shutdownNotice is sent to plain games when the server shuts down
+const shutdownNotice = "server restarting, your progress is saved"
+
+// timeoutNotice is sent to plain games before they are disconnected by a session timeout
+const timeoutNotice = "session timed out, your progress is saved"
+
+// plainMessage is a line of the plain protocol in JSON mode
+type plainMessage struct {
+	Type string `json:"type"` // start, feedback, error, shutdown, timeout or end
+
+	// start
+	Date         string `json:"date,omitempty"`
+	PuzzleNumber int    `json:"puzzle_number,omitempty"`
+	MaxGuesses   int    `json:"max_guesses,omitempty"`
+	HardMode     bool   `json:"hard_mode,omitempty"`
+
+	// feedback
+	Guess       string   `json:"guess,omitempty"`
+	GuessNumber int      `json:"guess_number,omitempty"`
+	Feedback    []string `json:"feedback,omitempty"`
+
+	// error, shutdown and timeout
+	Error string `json:"error,omitempty"`
+
+	// end
+	Won      *bool  `json:"won,omitempty"`
+	Score    string `json:"score,omitempty"`
+	Solution string `json:"solution,omitempty"`
+}
+
+// plainGame plays the daily puzzle over a line-based protocol: the client sends
+// one guess per line and receives the feedback for it, without a terminal
+type plainGame struct {
+	server   *Server
+	sess     ssh.Session
+	json     bool
+	username string
+	sshKey   string
+	date     string
+	game     *engine.Game
+	started  time.Time
+	timeout  *sessionTimeout
+	recorded bool // Another session recorded the game, its progress is no longer saved
+
+	// sendMu keeps the shutdown notice from interleaving with other lines
+	sendMu sync.Mutex
+}
+
+// runPlay handles "ssh <host> play --plain [--json]"
+func (s *Server) runPlay(sess ssh.Session, args []string) error {
+	fs := newFlagSet(sess, "play")
+	plain := fs.Bool("plain", false, "play over a line-based protocol instead of the terminal UI")
+	jsonOutput := fs.Bool("json", false, "send one JSON object per line")
+	if positional, err := parseArgs(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 || !*plain {
+		return errors.New("usage: ssh <host> play --plain [--json], connect without a command to play in your terminal")
+	}
+
+	username, sshKeyFingerprint := sessionIdentity(sess)
+	settings := s.loadUserSettings(username, sshKeyFingerprint)
+
+	puzzle, err := s.words.Puzzle(settings.Today())
+	if err != nil {
+		s.config.Logger.Error("No Wordle word available for session", "error", err, "date", settings.Today())
+		return errors.New("today's puzzle is not available yet, please try again later")
+	}
+
+	hasPlayed, err := s.statsStore.HasPlayedToday(username, sshKeyFingerprint, puzzle.Date)
+	if err != nil {
+		s.config.Logger.Error("Failed to check if user played today", "error", err, "username", username)
+		return errors.New("failed to load your game, please try again later")
+	}
+
+	if hasPlayed {
+		return errors.New("you've already played today, run \"ssh <host> share\" to see your result")
+	}
+
+	g := &plainGame{
+		server:   s,
+		sess:     sess,
+		json:     *jsonOutput,
+		username: username,
+		sshKey:   sshKeyFingerprint,
+		date:     puzzle.Date,
+		game:     engine.New(puzzle.Solution, settings.HardMode),
+		started:  time.Now(),
+		timeout:  s.newSessionTimeout(),
+	}
+
+	// Continue the game if it was left unfinished, in the TUI or here
+	progress, err := s.statsStore.GetGameProgress(username, sshKeyFingerprint, puzzle.Date)
+	if err != nil {
+		s.config.Logger.Error("Failed to get game progress", "error", err, "username", username)
+	} else if progress != nil && progress.Mode == stats.GameModeDaily {
+		g.game = progress.Game
+		g.started = progress.StartedAt
+	}
+
+	if g.game.GuessCount() == 0 {
+		metrics.GamesStarted.WithLabelValues(string(stats.GameModeDaily)).Inc()
+	}
+
+	s.sessions.OnShutdown(sess, func() {
+		g.send(plainMessage{Type: "shutdown", Error: shutdownNotice}, "SHUTDOWN "+shutdownNotice)
+	})
+
+	// Unfinished games are saved after every guess, so the session can be closed right away
+	go g.timeout.Run(sess.Context(), func(time.Duration, bool) {}, func(idle bool) {
+		s.config.Logger.Info("Session timed out", "username", username, "idle", idle)
+		g.send(plainMessage{Type: "timeout", Error: timeoutNotice}, "TIMEOUT "+timeoutNotice)
+		_ = sess.Close()
+	})
+
+	s.config.Logger.Info("Starting plain game", "username", username, "word_date", puzzle.Date, "guesses", g.game.GuessCount())
+	return g.play(puzzle.Number)
+}
+
+// play sends the start of the game and handles guesses until it is over or the client disconnects
+func (g *plainGame) play(puzzleNumber int) error {
+	line := fmt.Sprintf("START %s %d %d", g.date, puzzleNumber, engine.MaxGuesses)
+	if g.game.HardMode() {
+		line += " HARD"
+	}
+
+	g.send(plainMessage{
+		Type:         "start",
+		Date:         g.date,
+		PuzzleNumber: puzzleNumber,
+		MaxGuesses:   engine.MaxGuesses,
+		HardMode:     g.game.HardMode(),
+	}, line)
+
+	// Replay the guesses of a resumed game
+	for i, feedback := range g.game.Feedback() {
+		g.sendFeedback(g.game.Guesses()[i], i+1, feedback)
+	}
+
+	scanner := bufio.NewScanner(g.timeout.Reader(g.sess))
+	for !g.game.IsOver() && scanner.Scan() {
+		guess := strings.TrimSpace(scanner.Text())
+		if guess == "" {
+			continue
+		}
+
+		feedback, err := g.game.Guess(guess)
+		if err != nil {
+			metrics.InvalidGuess(err)
+			g.send(plainMessage{Type: "error", Guess: guess, Error: guessError(err)}, "ERROR "+guessError(err))
+			continue
+		}
+
+		g.sendFeedback(strings.ToLower(guess), g.game.GuessCount(), feedback)
+
+		if !g.game.IsOver() {
+			g.saveProgress()
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read guess: %w", err)
+	}
+
+	// The progress is kept if the client left before the game was over
+	if !g.game.IsOver() {
+		return nil
+	}
+
+	return g.finish()
+}
+
+// finish records the finished game and sends its outcome
+func (g *plainGame) finish() error {
+	result := g.game.Result()
+	record := &stats.Game{
+		Username:          g.username,
+		SSHKeyFingerprint: g.sshKey,
+		WordDate:          g.date,
+		Mode:              stats.GameModeDaily,
+		HardMode:          g.game.HardMode(),
+		Guesses:           g.game.GuessCount(),
+		StartedAt:         g.started,
+	}
+
+	record.GameResult, _ = result.JSON()
+
+	var err error
+	if result.Won {
+		err = g.server.statsStore.RecordWin(record)
+	} else {
+		err = g.server.statsStore.RecordLoss(record)
+	}
+
+	switch {
+	case errors.Is(err, stats.ErrAlreadyRecorded):
+		// Another session of the same user finished this puzzle first
+		g.server.config.Logger.Warn("Game already recorded", "username", g.username, "word_date", g.date)
+	case err != nil:
+		g.server.config.Logger.Error("Failed to record plain game", "error", err, "username", g.username)
+		return errors.New("failed to record your game, please try again later")
+	}
+
+	outcome := "LOST"
+	if result.Won {
+		outcome = "WON"
+	}
+
+	g.send(plainMessage{
+		Type:     "end",
+		Won:      &result.Won,
+		Score:    result.Score(),
+		Solution: g.game.Solution(),
+	}, fmt.Sprintf("%s %s %s", outcome, result.Score(), strings.ToUpper(g.game.Solution())))
+
+	return nil
+}
+
+// saveProgress saves the unfinished game so it can be resumed
+func (g *plainGame) saveProgress() {
+	if g.recorded {
+		return
+	}
+
+	progress := &stats.GameProgress{
+		Username:          g.username,
+		SSHKeyFingerprint: g.sshKey,
+		WordDate:          g.date,
+		Mode:              stats.GameModeDaily,
+		Game:              g.game,
+		StartedAt:         g.started,
+	}
+
+	err := g.server.statsStore.SaveGameProgress(progress)
+	switch {
+	case errors.Is(err, stats.ErrAlreadyRecorded):
+		// Another session of the same user finished this puzzle first
+		g.server.config.Logger.Warn("Game already recorded, no longer saving progress", "username", g.username, "word_date", g.date)
+		g.recorded = true
+	case err != nil:
+		g.server.config.Logger.Error("Failed to save game progress", "error", err, "username", g.username)
+	}
+}
+
+// sendFeedback sends the feedback for a guess
+func (g *plainGame) sendFeedback(guess string, guessNumber int, feedback engine.Feedback) {
+	words := make([]string, 0, len(feedback))
+	states := make([]string, 0, len(feedback))
+	for _, letter := range feedback {
+		words = append(words, feedbackWords[letter.State])
+		states = append(states, strings.ToLower(feedbackWords[letter.State]))
+	}
+
+	g.send(plainMessage{Type: "feedback", Guess: guess, GuessNumber: guessNumber, Feedback: states}, strings.Join(words, " "))
+}
+
+// send writes a message as JSON or as its plain text line
+func (g *plainGame) send(message plainMessage, line string) {
+	g.sendMu.Lock()
+	defer g.sendMu.Unlock()
+
+	if g.json {
+		if err := json.NewEncoder(g.sess).Encode(message); err != nil {
+			g.server.config.Logger.Debug("Failed to send plain message", "error", err, "username", g.username)
+		}
+		return
+	}
+
+	wish.Println(g.sess, line)
+}
+
+// guessError returns the message for a rejected guess
+func guessError(err error) string {
+	var hardModeErr *engine.HardModeError
+
+	switch {
+	case errors.Is(err, engine.ErrWrongLength):
+		return fmt.Sprintf("word must be %d letters", engine.WordLength)
+	case errors.Is(err, engine.ErrNotInWordList):
+		return "invalid word"
+	case errors.As(err, &hardModeErr):
+		return hardModeErr.Message
+	default:
+		return "could not submit guess"
+	}
+}
@@ -0,0 +1,44 @@
+package server
+
+import (
+	"math/rand/v2"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+	"github.com/f-gillmann/wordle-ssh/internal/wordle"
+)
+
+// practiceWords picks the solutions of guest practice games
+type practiceWords struct {
+	store  *stats.Store
+	logger *log.Logger
+	now    func() time.Time
+}
+
+// newPracticeWords creates a picker for solutions from the store
+func newPracticeWords(store *stats.Store, logger *log.Logger) *practiceWords {
+	return &practiceWords{
+		store:  store,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// PracticeWord returns a random past solution that is no longer in play in
+// any timezone, so practice never spoils a daily puzzle. Until a past
+// solution is stored a random valid word is used.
+func (p *practiceWords) PracticeWord() string {
+	before := p.now().Add(earliestOffset).UTC().Format(stats.DateFormat)
+
+	word, err := p.store.RandomDailyWord(before)
+	if err != nil {
+		p.logger.Error("Failed to get practice word", "error", err)
+	}
+
+	if word != nil {
+		return word.Solution
+	}
+
+	return wordle.ValidWords[rand.IntN(len(wordle.ValidWords))]
+}
@@ -0,0 +1,274 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// rateLimitPruneInterval is how often idle buckets and expired bans are dropped
+const rateLimitPruneInterval = time.Minute
+
+// tokenBucket allows bursts of connections, refilled at a steady rate
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// take refills the bucket and takes a token if one is left
+func (b *tokenBucket) take(now time.Time, perSecond float64, burst float64) bool {
+	b.tokens = min(burst, b.tokens+now.Sub(b.last).Seconds()*perSecond)
+	b.last = now
+
+	if b.tokens < 1 {
+		return false
+	}
+
+	b.tokens--
+	return true
+}
+
+// authFailures counts the authentication failures of an IP within a window
+type authFailures struct {
+	count int
+	first time.Time
+}
+
+// rateLimiter limits connection rates per IP and SSH key, concurrent sessions
+// per IP and SSH key, and bans IPs after repeated authentication failures
+type rateLimiter struct {
+	config RateLimitConfig
+	logger *log.Logger
+	now    func() time.Time
+
+	mu          sync.Mutex
+	ipBuckets   map[string]*tokenBucket
+	keyBuckets  map[string]*tokenBucket
+	ipSessions  map[string]int
+	keySessions map[string]int
+	failures    map[string]*authFailures
+	bans        map[string]time.Time
+	lastPrune   time.Time
+}
+
+// newRateLimiter creates a rate limiter, zero values in the config disable a limit
+func newRateLimiter(config RateLimitConfig, logger *log.Logger) *rateLimiter {
+	return &rateLimiter{
+		config:      config,
+		logger:      logger,
+		now:         time.Now,
+		ipBuckets:   make(map[string]*tokenBucket),
+		keyBuckets:  make(map[string]*tokenBucket),
+		ipSessions:  make(map[string]int),
+		keySessions: make(map[string]int),
+		failures:    make(map[string]*authFailures),
+		bans:        make(map[string]time.Time),
+	}
+}
+
+// AllowConn drops connections from banned IPs and IPs connecting too often
+// before the SSH handshake, it is used as the server's connection callback
+func (r *rateLimiter) AllowConn(ctx ssh.Context, conn net.Conn) net.Conn {
+	ip := remoteIP(conn.RemoteAddr())
+	if ip == nil {
+		return conn
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.prune(now)
+
+	if until, ok := r.bans[ip.String()]; ok && now.Before(until) {
+		metrics.RateLimited.WithLabelValues("banned").Inc()
+		return nil
+	}
+
+	if !r.take(r.ipBuckets, ip.String(), r.config.IPPerMinute, now) {
+		r.logger.Debug("Rate limited connection", "address", conn.RemoteAddr())
+		metrics.RateLimited.WithLabelValues("ip_rate").Inc()
+		return nil
+	}
+
+	return conn
+}
+
+// ConnectionFailed counts authentication failures and bans IPs with too many,
+// it is used as the server's connection failed callback
+func (r *rateLimiter) ConnectionFailed(conn net.Conn, err error) {
+	var authErr *gossh.ServerAuthError
+	if r.config.BanAfterFailures <= 0 || !errors.As(err, &authErr) {
+		return
+	}
+
+	ip := remoteIP(conn.RemoteAddr())
+	if ip == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	failures, ok := r.failures[ip.String()]
+	if !ok || now.Sub(failures.first) > r.config.BanWindow {
+		failures = &authFailures{first: now}
+		r.failures[ip.String()] = failures
+	}
+
+	failures.count++
+	if failures.count < r.config.BanAfterFailures {
+		return
+	}
+
+	delete(r.failures, ip.String())
+	r.bans[ip.String()] = now.Add(r.config.BanDuration)
+
+	r.logger.Warn("Banned IP after repeated authentication failures", "ip", ip, "failures", failures.count, "duration", r.config.BanDuration)
+	metrics.Bans.Inc()
+	metrics.BannedIPs.Set(float64(len(r.bans)))
+}
+
+// Middleware limits the connection rate per SSH key and the concurrent
+// sessions per IP and SSH key
+func (r *rateLimiter) Middleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			ip := ""
+			if addr := remoteIP(sess.RemoteAddr()); addr != nil {
+				ip = addr.String()
+			}
+
+			_, sshKeyFingerprint := sessionIdentity(sess)
+
+			if reason := r.acquire(ip, sshKeyFingerprint); reason != "" {
+				r.logger.Info("Rate limited session", "username", sess.User(), "address", sess.RemoteAddr(), "reason", reason)
+				metrics.RateLimited.WithLabelValues(reason).Inc()
+
+				if reason == "key_rate" {
+					wish.Fatalln(sess, "Too many connections, please try again in a minute.")
+				} else {
+					wish.Fatalln(sess, "Too many open sessions, please close one and try again.")
+				}
+				return
+			}
+
+			defer r.release(ip, sshKeyFingerprint)
+			next(sess)
+		}
+	}
+}
+
+// acquire checks the key rate and session limits and counts the session,
+// it returns the reason if the session is rejected
+func (r *rateLimiter) acquire(ip string, sshKeyFingerprint string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if sshKeyFingerprint != "" && !r.take(r.keyBuckets, sshKeyFingerprint, r.config.KeyPerMinute, r.now()) {
+		return "key_rate"
+	}
+
+	if ip != "" && r.config.MaxSessionsPerIP > 0 && r.ipSessions[ip] >= r.config.MaxSessionsPerIP {
+		return "ip_sessions"
+	}
+
+	if sshKeyFingerprint != "" && r.config.MaxSessionsPerKey > 0 && r.keySessions[sshKeyFingerprint] >= r.config.MaxSessionsPerKey {
+		return "key_sessions"
+	}
+
+	if ip != "" {
+		r.ipSessions[ip]++
+	}
+
+	if sshKeyFingerprint != "" {
+		r.keySessions[sshKeyFingerprint]++
+	}
+
+	return ""
+}
+
+// release stops counting a session
+func (r *rateLimiter) release(ip string, sshKeyFingerprint string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	decrement := func(sessions map[string]int, key string) {
+		if key == "" {
+			return
+		}
+
+		if sessions[key] <= 1 {
+			delete(sessions, key)
+		} else {
+			sessions[key]--
+		}
+	}
+
+	decrement(r.ipSessions, ip)
+	decrement(r.keySessions, sshKeyFingerprint)
+}
+
+// take takes a token from the bucket of a client, the caller holds the lock
+func (r *rateLimiter) take(buckets map[string]*tokenBucket, client string, perMinute int, now time.Time) bool {
+	if perMinute <= 0 {
+		return true
+	}
+
+	bucket, ok := buckets[client]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(r.config.Burst), last: now}
+		buckets[client] = bucket
+	}
+
+	return bucket.take(now, float64(perMinute)/60, float64(r.config.Burst))
+}
+
+// prune drops full buckets, old failures and expired bans so the maps don't
+// grow with every client ever seen, the caller holds the lock
+func (r *rateLimiter) prune(now time.Time) {
+	if now.Sub(r.lastPrune) < rateLimitPruneInterval {
+		return
+	}
+	r.lastPrune = now
+
+	r.pruneBuckets(r.ipBuckets, r.config.IPPerMinute, now)
+	r.pruneBuckets(r.keyBuckets, r.config.KeyPerMinute, now)
+
+	for ip, failures := range r.failures {
+		if now.Sub(failures.first) > r.config.BanWindow {
+			delete(r.failures, ip)
+		}
+	}
+
+	for ip, until := range r.bans {
+		if !now.Before(until) {
+			delete(r.bans, ip)
+			r.logger.Info("Ban expired", "ip", ip)
+		}
+	}
+
+	metrics.BannedIPs.Set(float64(len(r.bans)))
+}
+
+// pruneBuckets drops buckets that have refilled completely, the caller holds the lock
+func (r *rateLimiter) pruneBuckets(buckets map[string]*tokenBucket, perMinute int, now time.Time) {
+	if perMinute <= 0 {
+		return
+	}
+
+	refill := time.Duration(float64(r.config.Burst) / float64(perMinute) * float64(time.Minute))
+	for client, bucket := range buckets {
+		if now.Sub(bucket.last) >= refill {
+			delete(buckets, client)
+		}
+	}
+}
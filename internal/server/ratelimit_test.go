@@ -0,0 +1,195 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/log"
+	gossh "golang.org/x/crypto/ssh"
+)
+
+// testConn is a connection from a remote address
+type testConn struct {
+	net.Conn
+	addr net.Addr
+}
+
+func (c testConn) RemoteAddr() net.Addr {
+	return c.addr
+}
+
+// connFrom returns a connection from an IP
+func connFrom(ip string) net.Conn {
+	return testConn{addr: &net.TCPAddr{IP: net.ParseIP(ip), Port: 50000}}
+}
+
+// testClock is the time of a rate limiter under test
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+// newTestRateLimiter creates a rate limiter that runs on a test clock
+func newTestRateLimiter(config RateLimitConfig) (*rateLimiter, *testClock) {
+	clock := &testClock{now: time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)}
+	limiter := newRateLimiter(config, log.New(io.Discard))
+	limiter.now = func() time.Time { return clock.now }
+	return limiter, clock
+}
+
+func TestTokenBucket(t *testing.T) {
+	start := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	bucket := &tokenBucket{tokens: 3, last: start}
+
+	// A full bucket allows a burst, then rejects until it refills
+	for i := range 3 {
+		if !bucket.take(start, 1, 3) {
+			t.Fatalf("take() %d of the burst rejected", i+1)
+		}
+	}
+
+	if bucket.take(start, 1, 3) {
+		t.Error("take() of an empty bucket allowed")
+	}
+
+	if bucket.take(start.Add(500*time.Millisecond), 1, 3) {
+		t.Error("take() before a whole token refilled allowed")
+	}
+
+	if !bucket.take(start.Add(1500*time.Millisecond), 1, 3) {
+		t.Error("take() after a token refilled rejected")
+	}
+
+	// A long pause refills the bucket up to the burst, not beyond
+	later := start.Add(time.Hour)
+	for i := range 3 {
+		if !bucket.take(later, 1, 3) {
+			t.Fatalf("take() %d after refilling rejected", i+1)
+		}
+	}
+
+	if bucket.take(later, 1, 3) {
+		t.Error("take() beyond the burst allowed after a long pause")
+	}
+}
+
+func TestAllowConnLimitsRatePerIP(t *testing.T) {
+	limiter, clock := newTestRateLimiter(RateLimitConfig{IPPerMinute: 60, Burst: 2})
+
+	for i := range 2 {
+		if limiter.AllowConn(nil, connFrom("192.0.2.1")) == nil {
+			t.Fatalf("AllowConn() %d of the burst rejected", i+1)
+		}
+	}
+
+	if limiter.AllowConn(nil, connFrom("192.0.2.1")) != nil {
+		t.Error("AllowConn() beyond the burst allowed")
+	}
+
+	if limiter.AllowConn(nil, connFrom("192.0.2.2")) == nil {
+		t.Error("AllowConn() of another IP rejected")
+	}
+
+	clock.advance(time.Second)
+	if limiter.AllowConn(nil, connFrom("192.0.2.1")) == nil {
+		t.Error("AllowConn() after a token refilled rejected")
+	}
+}
+
+func TestBanAfterFailures(t *testing.T) {
+	limiter, clock := newTestRateLimiter(RateLimitConfig{BanAfterFailures: 3, BanWindow: time.Minute, BanDuration: 10 * time.Minute})
+	authErr := &gossh.ServerAuthError{}
+
+	// Failures spread over more than the window don't ban
+	limiter.ConnectionFailed(connFrom("192.0.2.1"), authErr)
+	limiter.ConnectionFailed(connFrom("192.0.2.1"), authErr)
+	clock.advance(2 * time.Minute)
+	limiter.ConnectionFailed(connFrom("192.0.2.1"), authErr)
+
+	// Other errors are no authentication failures
+	limiter.ConnectionFailed(connFrom("192.0.2.1"), errors.New("connection reset"))
+
+	if limiter.AllowConn(nil, connFrom("192.0.2.1")) == nil {
+		t.Fatal("AllowConn() rejected an IP without enough failures in the window")
+	}
+
+	limiter.ConnectionFailed(connFrom("192.0.2.1"), authErr)
+	limiter.ConnectionFailed(connFrom("192.0.2.1"), authErr)
+
+	if limiter.AllowConn(nil, connFrom("192.0.2.1")) != nil {
+		t.Fatal("AllowConn() allowed an IP after three failures in the window")
+	}
+
+	if limiter.AllowConn(nil, connFrom("192.0.2.2")) == nil {
+		t.Error("AllowConn() rejected another IP")
+	}
+
+	clock.advance(9 * time.Minute)
+	if limiter.AllowConn(nil, connFrom("192.0.2.1")) != nil {
+		t.Error("AllowConn() allowed a banned IP before the ban expired")
+	}
+
+	clock.advance(time.Minute)
+	if limiter.AllowConn(nil, connFrom("192.0.2.1")) == nil {
+		t.Error("AllowConn() rejected an IP after the ban expired")
+	}
+
+	if _, ok := limiter.bans["192.0.2.1"]; ok {
+		t.Error("expired ban was not pruned")
+	}
+}
+
+func TestPruneKeepsBucketsNotRefilled(t *testing.T) {
+	// An empty bucket refills its burst of 10 in 10 seconds
+	limiter, clock := newTestRateLimiter(RateLimitConfig{IPPerMinute: 60, Burst: 10})
+	start := clock.now
+
+	limiter.ipBuckets["192.0.2.1"] = &tokenBucket{tokens: 0, last: start}
+	limiter.ipBuckets["192.0.2.2"] = &tokenBucket{tokens: 0, last: start.Add(5 * time.Second)}
+
+	limiter.pruneBuckets(limiter.ipBuckets, limiter.config.IPPerMinute, start.Add(10*time.Second))
+
+	if _, ok := limiter.ipBuckets["192.0.2.1"]; ok {
+		t.Error("refilled bucket was not pruned")
+	}
+
+	// Dropping this bucket would give the client a full burst early
+	if _, ok := limiter.ipBuckets["192.0.2.2"]; !ok {
+		t.Error("bucket that is not refilled yet was pruned")
+	}
+}
+
+func TestSessionLimits(t *testing.T) {
+	limiter, _ := newTestRateLimiter(RateLimitConfig{MaxSessionsPerIP: 2, MaxSessionsPerKey: 1})
+
+	if reason := limiter.acquire("192.0.2.1", "SHA256:alice"); reason != "" {
+		t.Fatalf("acquire() rejected the first session: %s", reason)
+	}
+
+	if reason := limiter.acquire("192.0.2.2", "SHA256:alice"); reason != "key_sessions" {
+		t.Errorf("acquire() of a second session of a key = %q, want key_sessions", reason)
+	}
+
+	if reason := limiter.acquire("192.0.2.1", "SHA256:bob"); reason != "" {
+		t.Errorf("acquire() of a second session of an IP rejected: %s", reason)
+	}
+
+	if reason := limiter.acquire("192.0.2.1", ""); reason != "ip_sessions" {
+		t.Errorf("acquire() of a third session of an IP = %q, want ip_sessions", reason)
+	}
+
+	limiter.release("192.0.2.1", "SHA256:alice")
+	if reason := limiter.acquire("192.0.2.2", "SHA256:alice"); reason != "" {
+		t.Errorf("acquire() after the session was released rejected: %s", reason)
+	}
+
+	if len(limiter.ipSessions) != 2 || limiter.ipSessions["192.0.2.1"] != 1 {
+		t.Errorf("sessions per IP = %v, want one for each IP", limiter.ipSessions)
+	}
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+)
+
+const (
+	// prefetchLead is how long before a day starts anywhere its word is fetched
+	prefetchLead = time.Hour
+	// retryInterval is how long to wait before retrying a failed fetch
+	retryInterval = 5 * time.Minute
+	// schedulerInterval is how often the set of active dates is refreshed
+	schedulerInterval = 15 * time.Minute
+
+	// Timezones range from UTC-12 to UTC+14, so at any instant some player
+	// may be on any of up to three different puzzle dates
+	earliestOffset = -12 * time.Hour
+	latestOffset   = 14 * time.Hour
+)
+
+// wordScheduler keeps the puzzles for every date currently in play loaded,
+// prefetches upcoming dates ahead of time and drops dates no longer in play
+type wordScheduler struct {
+	load   func(date string) (*stats.DailyWord, error)
+	logger *log.Logger
+	now    func() time.Time
+
+	mu    sync.RWMutex
+	words map[string]*stats.DailyWord
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newWordScheduler creates a scheduler that loads words with the given function
+func newWordScheduler(load func(date string) (*stats.DailyWord, error), logger *log.Logger) *wordScheduler {
+	return &wordScheduler{
+		load:   load,
+		logger: logger,
+		now:    time.Now,
+		words:  make(map[string]*stats.DailyWord),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+}
+
+// Start loads the active dates and starts the background prefetch goroutine
+func (w *wordScheduler) Start() {
+	wait := w.tick()
+
+	go func() {
+		defer close(w.done)
+
+		for {
+			timer := time.NewTimer(wait)
+
+			select {
+			case <-w.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+				wait = w.tick()
+			}
+		}
+	}()
+}
+
+// Stop stops the background goroutine and waits for it to exit
+func (w *wordScheduler) Stop() {
+	close(w.stop)
+	<-w.done
+}
+
+// Puzzle returns the puzzle for a date, loading it if it is not cached
+func (w *wordScheduler) Puzzle(date string) (*stats.DailyWord, error) {
+	w.mu.RLock()
+	word, ok := w.words[date]
+	w.mu.RUnlock()
+
+	if ok {
+		return word, nil
+	}
+
+	word, err := w.load(date)
+	if err != nil {
+		return nil, err
+	}
+
+	w.mu.Lock()
+	w.words[date] = word
+	w.mu.Unlock()
+
+	return word, nil
+}
+
+// Loaded reports whether the puzzle for a date is cached
+func (w *wordScheduler) Loaded(date string) bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	_, ok := w.words[date]
+	return ok
+}
+
+// activeDates returns every puzzle date that is or will soon be in play somewhere
+func (w *wordScheduler) activeDates(now time.Time) []string {
+	first := now.Add(earliestOffset).UTC()
+	last := now.Add(latestOffset + prefetchLead).UTC().Format(stats.DateFormat)
+
+	var dates []string
+	for day := first; ; day = day.AddDate(0, 0, 1) {
+		date := day.Format(stats.DateFormat)
+		dates = append(dates, date)
+
+		if date >= last {
+			return dates
+		}
+	}
+}
+
+// tick loads missing active dates, evicts stale ones and returns the time until the next check
+func (w *wordScheduler) tick() time.Duration {
+	dates := w.activeDates(w.now())
+	wait := schedulerInterval
+
+	for _, date := range dates {
+		w.mu.RLock()
+		_, ok := w.words[date]
+		w.mu.RUnlock()
+
+		if ok {
+			continue
+		}
+
+		word, err := w.load(date)
+		if err != nil {
+			w.logger.Warn("Failed to prefetch Wordle word", "date", date, "error", err)
+			wait = retryInterval
+			continue
+		}
+
+		w.mu.Lock()
+		w.words[date] = word
+		w.mu.Unlock()
+
+		w.logger.Info("Prefetched Wordle word", "date", date)
+	}
+
+	w.mu.Lock()
+	for date := range w.words {
+		if date < dates[0] {
+			delete(w.words, date)
+		}
+	}
+	w.mu.Unlock()
+
+	return wait
+}
@@ -2,19 +2,24 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
-	"github.com/charmbracelet/log"
 	"github.com/charmbracelet/ssh"
 	"github.com/charmbracelet/wish"
 	"github.com/charmbracelet/wish/activeterm"
 	"github.com/charmbracelet/wish/bubbletea"
 	"github.com/charmbracelet/wish/logging"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
 	"github.com/f-gillmann/wordle-ssh/internal/stats"
 	"github.com/f-gillmann/wordle-ssh/internal/ui"
 	"github.com/f-gillmann/wordle-ssh/internal/wordle"
@@ -22,81 +27,64 @@ import (
 	gossh "golang.org/x/crypto/ssh"
 )
 
-const (
-	defaultPort        = "23234"
-	defaultHostKeyPath = ".ssh/id_ed25519"
-	defaultDBPath      = "./wordle-stats.db"
-	defaultMOTD        = "Welcome to Wordle SSH!"
-)
-
-// Config holds the server configuration
-type Config struct {
-	Host        string
-	Port        string
-	HostKeyPath string
-	DBPath      string
-	MOTD        string
-	Logger      *log.Logger
-	LogLevel    log.Level
-}
-
-// LoadConfigFromEnv loads configuration from environment variables
-func LoadConfigFromEnv() Config {
-	host := os.Getenv("WORDLE_SSH_HOST")
-
-	port := os.Getenv("WORDLE_SSH_PORT")
-	if port == "" {
-		port = defaultPort
-	}
+// drainTimeout bounds the steps of a shutdown after the sessions were closed
+const drainTimeout = 5 * time.Second
 
-	hostKeyPath := os.Getenv("WORDLE_SSH_HOST_KEY_PATH")
-	if hostKeyPath == "" {
-		hostKeyPath = defaultHostKeyPath
+// NewWordSource creates the word source chain from the configuration
+func NewWordSource(config Config) (wordle.WordSource, error) {
+	if config.WordSource != nil {
+		return config.WordSource, nil
 	}
 
-	dbPath := os.Getenv("WORDLE_SSH_DB_PATH")
-	if dbPath == "" {
-		dbPath = defaultDBPath
+	names := config.WordSources
+	if len(names) == 0 {
+		names = splitList(defaultWordSources)
 	}
 
-	motd := os.Getenv("WORDLE_SSH_MOTD")
-	if motd == "" {
-		motd = defaultMOTD
+	var sources []wordle.WordSource
+	for _, name := range names {
+		switch strings.ToLower(name) {
+		case "nyt":
+			sources = append(sources, wordle.NewNYTSource(config.NYTTimeout))
+		case "schedule":
+			if config.SchedulePath == "" {
+				return nil, fmt.Errorf("word source %q requires a schedule path", name)
+			}
+
+			schedule, err := wordle.NewScheduleSource(config.SchedulePath)
+			if err != nil {
+				return nil, err
+			}
+
+			config.Logger.Info("Loaded word schedule", "path", config.SchedulePath, "puzzles", schedule.Len())
+			sources = append(sources, schedule)
+		default:
+			return nil, fmt.Errorf("unknown word source %q", name)
+		}
 	}
 
-	logLevel := os.Getenv("WORDLE_SSH_LOG_LEVEL")
-	var level log.Level
-
-	switch logLevel {
-	case "debug":
-		level = log.DebugLevel
-	case "info":
-		level = log.InfoLevel
-	case "warn":
-		level = log.WarnLevel
-	case "error":
-		level = log.ErrorLevel
-	default:
-		level = log.InfoLevel
+	if len(sources) == 1 {
+		return sources[0], nil
 	}
 
-	return Config{
-		Host:        host,
-		Port:        port,
-		HostKeyPath: hostKeyPath,
-		DBPath:      dbPath,
-		MOTD:        motd,
-		LogLevel:    level,
-	}
+	return wordle.NewChainSource(sources...), nil
 }
 
 // Server represents the SSH server
 type Server struct {
 	config     Config
-	wordleWord string
-	wordleDate string
+	wordSource wordle.WordSource
+	words      *wordScheduler
+	practice   *practiceWords
+	finalizer  *gameFinalizer
+	sessions   *sessionRegistry
 	wishServer *ssh.Server
+	httpServer *http.Server
 	statsStore *stats.Store
+
+	// listening and shuttingDown are reported by the readiness check
+	listening    atomic.Bool
+	shuttingDown atomic.Bool
 }
 
 // New creates a new SSH server
@@ -113,12 +101,17 @@ func New(config Config) (*Server, error) {
 		config.DBPath = defaultDBPath
 	}
 
+	if config.ShutdownTimeout <= 0 {
+		config.ShutdownTimeout = defaultShutdownTimeout
+	}
+
 	if config.Logger == nil {
 		return nil, fmt.Errorf("logger must be provided in config")
 	}
 
 	s := &Server{
-		config: config,
+		config:   config,
+		sessions: newSessionRegistry(),
 	}
 
 	// Initialize stats store
@@ -127,32 +120,53 @@ func New(config Config) (*Server, error) {
 		return nil, fmt.Errorf("failed to initialize stats store: %w", err)
 	}
 	s.statsStore = statsStore
+	statsStore.SetLeaderboardConfig(stats.LeaderboardConfig{
+		OptIn:    config.LeaderboardOptIn,
+		MinGames: config.LeaderboardMinGames,
+	})
 
-	// Fetch today's Wordle word
-	if err := s.refreshWordleWord(); err != nil {
-		return nil, fmt.Errorf("failed to fetch wordle word: %w", err)
+	wordSource, err := NewWordSource(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure word source: %w", err)
 	}
+	s.wordSource = wordSource
+	s.words = newWordScheduler(s.loadDailyWord, config.Logger)
+	s.practice = newPracticeWords(statsStore, config.Logger)
+	s.finalizer = newGameFinalizer(statsStore, config.Logger)
 
-	checkBlacklist := func(ctx ssh.Context) bool {
-		username := ctx.User()
-		if stats.IsBlacklisted(username) {
-			config.Logger.Info("Blocked connection from blacklisted user", "username", username, "address", ctx.RemoteAddr(), "client", ctx.ClientVersion())
-			return false
-		}
-
-		return true
-	}
+	access := newAccessControl(statsStore, config.AllowlistOnly, config.Logger)
+	limiter := newRateLimiter(config.RateLimit, config.Logger)
 
 	// Create wish server with bubbletea middleware
 	wishServer, err := wish.NewServer(
 		wish.WithAddress(fmt.Sprintf("%s:%s", config.Host, config.Port)),
 		wish.WithHostKeyPath(config.HostKeyPath),
+		ssh.WrapConn(limiter.AllowConn),
+		// ssh has no option for the connection failed callback
+		func(srv *ssh.Server) error {
+			srv.ConnectionFailedCallback = limiter.ConnectionFailed
+			return nil
+		},
 		wish.WithPublicKeyAuth(func(ctx ssh.Context, key ssh.PublicKey) bool {
-			return checkBlacklist(ctx)
+			return access.Allow(ctx, gossh.FingerprintSHA256(key))
 		}),
+		// Clients try their keys first, keyboard-interactive admits users without one as guests
+		func(srv *ssh.Server) error {
+			if !config.AllowGuests {
+				return nil
+			}
+
+			return wish.WithKeyboardInteractiveAuth(func(ctx ssh.Context, _ gossh.KeyboardInteractiveChallenge) bool {
+				return access.AllowGuest(ctx)
+			})(srv)
+		},
 		wish.WithMiddleware(
-			bubbletea.MiddlewareWithColorProfile(s.teaHandler, termenv.ANSI256),
+			bubbletea.MiddlewareWithProgramHandler(s.programHandler, termenv.ANSI256),
 			activeterm.Middleware(),
+			s.commandMiddleware(),
+			sessionMetricsMiddleware(),
+			s.sessions.Middleware(),
+			limiter.Middleware(),
 			logging.StructuredMiddlewareWithLogger(config.Logger, config.LogLevel),
 		),
 	)
@@ -162,64 +176,145 @@ func New(config Config) (*Server, error) {
 	}
 
 	s.wishServer = wishServer
+
+	if config.HTTPAddr != "" {
+		s.httpServer = s.newHTTPServer()
+	}
+
 	return s, nil
 }
 
-// refreshWordleWord fetches the Wordle word only if it's a new day
-func (s *Server) refreshWordleWord() error {
-	today := time.Now().Format("2006-01-02")
+// loadDailyWord returns the stored word for a date, fetching and storing it on first use
+func (s *Server) loadDailyWord(date string) (*stats.DailyWord, error) {
+	word, fetched, err := LoadDailyWord(s.statsStore, s.wordSource, date)
+	if err != nil {
+		return nil, err
+	}
 
-	// Only fetch if we don't have a word yet or if the date has changed
-	if s.wordleWord == "" || s.wordleDate != today {
-		word, err := wordle.FetchTodayWord()
-		if err != nil {
-			return fmt.Errorf("failed to fetch wordle word: %w", err)
-		}
+	if fetched {
+		s.config.Logger.Info("Fetched Wordle word", "date", word.Date, "word", word.Solution, "source", word.Source)
+	} else {
+		s.config.Logger.Debug("Loaded stored Wordle word", "date", date, "source", word.Source)
+	}
 
-		s.wordleWord = word
-		s.wordleDate = today
+	return word, nil
+}
 
-		s.config.Logger.Info("Fetched Wordle word", "date", s.wordleDate, "word", s.wordleWord)
+// LoadDailyWord returns the stored word for a date, fetching it from the
+// source and storing it on first use. A word never changes once it has been
+// stored, fetched reports whether it was fetched now.
+func LoadDailyWord(store *stats.Store, source wordle.WordSource, date string) (word *stats.DailyWord, fetched bool, err error) {
+	word, err = store.GetDailyWord(date)
+	if err != nil {
+		return nil, false, err
 	}
 
-	return nil
-}
+	if word != nil {
+		return word, false, nil
+	}
 
-// teaHandler creates a bubbletea program for each SSH session
-func (s *Server) teaHandler(sshSession ssh.Session) (tea.Model, []tea.ProgramOption) {
-	// Refresh Wordle word if it's a new day
-	if err := s.refreshWordleWord(); err != nil {
-		s.config.Logger.Error("Failed to refresh Wordle word", "error", err)
-		return nil, nil
+	start := time.Now()
+	puzzle, err := source.FetchPuzzle(date)
+	metrics.WordFetchDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.WordFetchFailures.Inc()
+		return nil, false, fmt.Errorf("failed to fetch wordle word: %w", err)
+	}
+
+	word, err = store.SaveDailyWord(stats.DailyWord{
+		Date:     puzzle.Date,
+		Solution: puzzle.Solution,
+		Source:   puzzle.Source,
+		Number:   puzzle.Number,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to store wordle word: %w", err)
 	}
 
-	// Get username from SSH session
-	username := sshSession.User()
+	return word, true, nil
+}
+
+// sessionIdentity returns the username and SSH key fingerprint of a session,
+// the fingerprint is empty for guests
+func sessionIdentity(sess ssh.Session) (string, string) {
+	username := sess.User()
 	if username == "" {
 		username = "anonymous"
 	}
 
-	// Get SSH key fingerprint from session
-	sshKeyFingerprint := gossh.FingerprintSHA256(sshSession.PublicKey())
+	if isGuest(sess) {
+		return username, ""
+	}
+
+	return username, gossh.FingerprintSHA256(sess.PublicKey())
+}
+
+// isGuest reports whether a session logged in without an SSH key
+func isGuest(sess ssh.Session) bool {
+	return sess.PublicKey() == nil
+}
+
+// teaHandler creates a bubbletea program for each SSH session
+func (s *Server) teaHandler(sshSession ssh.Session) (tea.Model, []tea.ProgramOption) {
+	username, sshKeyFingerprint := sessionIdentity(sshSession)
+
+	// The server handles signals, sessions are told about a shutdown by the registry
+	opts := []tea.ProgramOption{tea.WithAltScreen(), tea.WithoutSignalHandler()}
+	opts = append(opts, bubbletea.MakeOptions(sshSession)...)
+
+	if isGuest(sshSession) {
+		s.config.Logger.Debug("Guest connecting", "username", username)
+		return ui.NewGuestAppModel(s.practice, username, newClipboard(sshSession), s.config.MOTD, s.config.Logger), opts
+	}
+
 	s.config.Logger.Debug("User connecting",
 		"username", username,
 		"ssh_key_fingerprint", sshKeyFingerprint,
 		"key_type", sshSession.PublicKey().Type(),
 	)
 
-	// Check if user has already played today
-	hasPlayed, err := s.statsStore.HasPlayedToday(username, sshKeyFingerprint, s.wordleDate)
-	if err != nil {
-		s.config.Logger.Error("Failed to check if user played today", "error", err, "username", username)
+	// Make sure the puzzle for the user's current date is available
+	settings := s.loadUserSettings(username, sshKeyFingerprint)
+	if _, err := s.words.Puzzle(settings.Today()); err != nil {
+		s.config.Logger.Error("No Wordle word available for session", "error", err, "date", settings.Today())
+		wish.Println(sshSession, "Today's puzzle is not available yet, please try again later.")
+		return nil, nil
 	}
 
-	// Create the app model with the current word, stats store, and logger
-	m := ui.NewAppModel(s.wordleWord, s.wordleDate, username, sshKeyFingerprint, s.statsStore, hasPlayed, s.config.MOTD, s.config.Logger)
+	// Create the app model, each game takes the current word when it starts
+	m := ui.NewAppModel(s.words, username, sshKeyFingerprint, s.statsStore, newClipboard(sshSession), s.config.MOTD, s.config.Logger)
+	return m, opts
+}
 
-	opts := []tea.ProgramOption{tea.WithAltScreen()}
-	opts = append(opts, bubbletea.MakeOptions(sshSession)...)
+// programHandler creates the bubbletea program of a session, registers it to
+// be told about a shutdown and enforces the session timeouts
+func (s *Server) programHandler(sshSession ssh.Session) *tea.Program {
+	m, opts := s.teaHandler(sshSession)
+	if m == nil {
+		return nil
+	}
 
-	return m, opts
+	// Keystrokes reset the idle timeout, the input replaces the session set by the options
+	timeout := s.newSessionTimeout()
+	opts = append(opts, tea.WithInput(timeout.Reader(sshSession)))
+
+	program := tea.NewProgram(m, opts...)
+	s.sessions.OnShutdown(sshSession, func() {
+		program.Send(ui.ShutdownMsg{})
+	})
+
+	go timeout.Run(sshSession.Context(), func(remaining time.Duration, idle bool) {
+		program.Send(ui.TimeoutWarningMsg{Remaining: remaining, Idle: idle})
+	}, func(idle bool) {
+		program.Send(ui.TimeoutMsg{Idle: idle})
+
+		// Close the session if the program does not quit in time
+		time.AfterFunc(timeoutGrace, func() {
+			_ = sshSession.Close()
+		})
+	})
+
+	return program
 }
 
 // Start starts the SSH server
@@ -229,26 +324,88 @@ func (s *Server) Start() error {
 
 	s.config.Logger.Info("Starting SSH server", "host", s.config.Host, "port", s.config.Port, "db", s.config.DBPath)
 
+	// Load today's word and keep it rolled over in the background
+	s.words.Start()
+
+	// Record games abandoned mid-way as losses once their day is over
+	s.finalizer.Start()
+
+	listener, err := net.Listen("tcp", s.wishServer.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", s.wishServer.Addr, err)
+	}
+
 	go func() {
-		if err := s.wishServer.ListenAndServe(); err != nil {
+		if err := s.wishServer.Serve(listener); err != nil && !errors.Is(err, ssh.ErrServerClosed) {
 			s.config.Logger.Fatal("Server error", "error", err)
 		}
 	}()
 
+	s.listening.Store(true)
+
+	if s.httpServer != nil {
+		s.config.Logger.Info("Starting HTTP server", "address", s.config.HTTPAddr)
+
+		go func() {
+			if err := s.httpServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				s.config.Logger.Error("HTTP server error", "error", err)
+			}
+		}()
+	}
+
 	<-done
-	s.config.Logger.Info("Stopping SSH server")
+	s.shutdown()
+	return nil
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+// shutdown stops accepting connections, lets open sessions finish their games
+// until the shutdown timeout and closes the database once they are gone
+func (s *Server) shutdown() {
+	s.config.Logger.Info("Stopping SSH server", "timeout", s.config.ShutdownTimeout)
+	s.shuttingDown.Store(true)
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.config.ShutdownTimeout)
 	defer cancel()
 
+	// Shutdown closes the listener right away and waits for open connections
+	shutdownErr := make(chan error, 1)
+	go func() {
+		shutdownErr <- s.wishServer.Shutdown(ctx)
+	}()
+
+	if count := s.sessions.Shutdown(); count > 0 {
+		s.config.Logger.Info("Waiting for open sessions", "sessions", count)
+	}
+
+	if err := <-shutdownErr; err != nil {
+		s.config.Logger.Warn("Closing sessions still open at the shutdown deadline", "error", err)
+		if err := s.wishServer.Close(); err != nil {
+			s.config.Logger.Error("Failed to close SSH server", "error", err)
+		}
+	}
+
+	// Closed sessions quit their programs, which may still be saving games
+	if !s.sessions.Wait(drainTimeout) {
+		s.config.Logger.Warn("Sessions did not stop in time, closing the database anyway")
+	}
+
+	s.words.Stop()
+	s.finalizer.Stop()
+
+	// The HTTP server goes after the sessions so readiness reports the shutdown until then
+	if s.httpServer != nil {
+		httpCtx, httpCancel := context.WithTimeout(context.Background(), drainTimeout)
+		defer httpCancel()
+
+		if err := s.httpServer.Shutdown(httpCtx); err != nil {
+			s.config.Logger.Error("Failed to shutdown HTTP server", "error", err)
+		}
+	}
+
 	// Close stats store
 	if err := s.statsStore.Close(); err != nil {
 		s.config.Logger.Error("Failed to close stats store", "error", err)
 	}
 
-	if err := s.wishServer.Shutdown(ctx); err != nil {
-		return fmt.Errorf("failed to shutdown server: %w", err)
-	}
-
-	return nil
+	s.config.Logger.Info("SSH server stopped")
 }
@@ -0,0 +1,103 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+)
+
+// sessionRegistry tracks open sessions so they can be told about a shutdown
+// and waited for before the database is closed
+type sessionRegistry struct {
+	mu       sync.Mutex
+	sessions map[ssh.Session]func()
+	closing  bool
+	wg       sync.WaitGroup
+}
+
+// newSessionRegistry creates an empty session registry
+func newSessionRegistry() *sessionRegistry {
+	return &sessionRegistry{
+		sessions: make(map[ssh.Session]func()),
+	}
+}
+
+// Middleware registers every session for as long as its handler runs
+func (r *sessionRegistry) Middleware() wish.Middleware {
+	return func(next ssh.Handler) ssh.Handler {
+		return func(sess ssh.Session) {
+			r.mu.Lock()
+			r.sessions[sess] = nil
+			r.wg.Add(1)
+			r.mu.Unlock()
+
+			defer func() {
+				r.mu.Lock()
+				delete(r.sessions, sess)
+				r.mu.Unlock()
+				r.wg.Done()
+			}()
+
+			next(sess)
+		}
+	}
+}
+
+// OnShutdown sets the function that tells a session the server is shutting
+// down. If the shutdown has already started it is called in the background
+// right away, as the session may not be ready to receive it yet: a program
+// only accepts messages once it runs.
+func (r *sessionRegistry) OnShutdown(sess ssh.Session, notify func()) {
+	r.mu.Lock()
+	closing := r.closing
+	if _, ok := r.sessions[sess]; ok {
+		r.sessions[sess] = notify
+	}
+	r.mu.Unlock()
+
+	if closing {
+		go notify()
+	}
+}
+
+// Shutdown tells every session the server is shutting down and returns the
+// number of open sessions
+func (r *sessionRegistry) Shutdown() int {
+	r.mu.Lock()
+	r.closing = true
+
+	notify := make([]func(), 0, len(r.sessions))
+	for _, fn := range r.sessions {
+		if fn != nil {
+			notify = append(notify, fn)
+		}
+	}
+
+	count := len(r.sessions)
+	r.mu.Unlock()
+
+	for _, fn := range notify {
+		fn()
+	}
+
+	return count
+}
+
+// Wait waits for all session handlers to return, reporting false if some
+// are still running after the timeout
+func (r *sessionRegistry) Wait(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
@@ -0,0 +1,402 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/ssh"
+	"github.com/charmbracelet/wish"
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+	"github.com/f-gillmann/wordle-ssh/internal/wordle"
+)
+
+const (
+	// defaultHistoryLimit is the number of games listed by the history command
+	defaultHistoryLimit = 30
+	// defaultLeaderboardLimit is the number of entries listed by the leaderboard command
+	defaultLeaderboardLimit = 10
+)
+
+// statsOutput is the JSON output of the stats command
+type statsOutput struct {
+	Username          string         `json:"username"`
+	GamesPlayed       int            `json:"games_played"`
+	GamesWon          int            `json:"games_won"`
+	GamesLost         int            `json:"games_lost"`
+	WinRate           float64        `json:"win_rate"`
+	CurrentStreak     int            `json:"current_streak"`
+	MaxStreak         int            `json:"max_streak"`
+	AverageGuesses    float64        `json:"average_guesses"`
+	GuessDistribution [6]int         `json:"guess_distribution"`
+	LastWordDate      string         `json:"last_word_date,omitempty"`
+	HardMode          hardModeOutput `json:"hard_mode"`
+}
+
+type hardModeOutput struct {
+	GamesPlayed    int     `json:"games_played"`
+	GamesWon       int     `json:"games_won"`
+	WinRate        float64 `json:"win_rate"`
+	AverageGuesses float64 `json:"average_guesses"`
+}
+
+// gameOutput is a finished game in the JSON output of the history command
+type gameOutput struct {
+	Date       string    `json:"date"`
+	Mode       string    `json:"mode"`
+	Won        bool      `json:"won"`
+	Guesses    int       `json:"guesses"`
+	HardMode   bool      `json:"hard_mode"`
+	FinishedAt time.Time `json:"finished_at"`
+}
+
+// shareOutput is the JSON output of the share command
+type shareOutput struct {
+	Date         string `json:"date"`
+	PuzzleNumber int    `json:"puzzle_number"`
+	Score        string `json:"score"`
+	Won          bool   `json:"won"`
+	Guesses      int    `json:"guesses"`
+	HardMode     bool   `json:"hard_mode"`
+	Text         string `json:"text"`
+}
+
+// leaderboardOutput is the JSON output of the leaderboard command, the
+// entries have the fields of the board
+type leaderboardOutput struct {
+	Board   string `json:"board"`
+	Date    string `json:"date"`
+	Entries []any  `json:"entries"`
+}
+
+// rankOutput is the position of a user on a leaderboard
+type rankOutput struct {
+	Rank     int    `json:"rank"`
+	Username string `json:"username"`
+}
+
+// dailyEntryOutput is an entry of the today leaderboard
+type dailyEntryOutput struct {
+	rankOutput
+	Guesses      int  `json:"guesses"`
+	HardMode     bool `json:"hard_mode"`
+	SolveSeconds *int `json:"solve_seconds"` // Null if the solve time is unknown
+}
+
+// winRateEntryOutput is an entry of the win rate leaderboard
+type winRateEntryOutput struct {
+	rankOutput
+	GamesPlayed int     `json:"games_played"`
+	GamesWon    int     `json:"games_won"`
+	WinRate     float64 `json:"win_rate"`
+}
+
+// streakEntryOutput is an entry of the streak leaderboards
+type streakEntryOutput struct {
+	rankOutput
+	CurrentStreak int `json:"current_streak"`
+	MaxStreak     int `json:"max_streak"`
+}
+
+// loadUserSettings returns the settings of a user, falling back to the defaults
+func (s *Server) loadUserSettings(username string, sshKeyFingerprint string) *stats.UserSettings {
+	settings, err := s.statsStore.GetUserSettings(username, sshKeyFingerprint)
+	if err != nil {
+		s.config.Logger.Error("Failed to get user settings", "error", err, "username", username)
+		return &stats.UserSettings{Username: username, SSHKeyFingerprint: sshKeyFingerprint}
+	}
+
+	return settings
+}
+
+// runStats prints the statistics of the user
+func (s *Server) runStats(sess ssh.Session, args []string) error {
+	fs := newFlagSet(sess, "stats")
+	jsonOutput := fs.Bool("json", false, "print JSON")
+	if positional, err := parseArgs(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		return errors.New("usage: ssh <host> stats [--json]")
+	}
+
+	username, sshKeyFingerprint := sessionIdentity(sess)
+	settings := s.loadUserSettings(username, sshKeyFingerprint)
+
+	userStats, err := s.statsStore.GetUserStats(username, sshKeyFingerprint)
+	if err != nil {
+		s.config.Logger.Error("Failed to get user stats", "error", err, "username", username)
+		return errors.New("failed to load your stats, please try again later")
+	}
+
+	// A stored streak is broken once the user misses a day in their timezone
+	userStats.CurrentStreak = userStats.CurrentStreakOn(settings.Today())
+
+	if *jsonOutput {
+		return writeJSON(sess, statsOutput{
+			Username:          username,
+			GamesPlayed:       userStats.GamesPlayed,
+			GamesWon:          userStats.GamesWon,
+			GamesLost:         userStats.GamesLost,
+			WinRate:           userStats.GetWinRate(),
+			CurrentStreak:     userStats.CurrentStreak,
+			MaxStreak:         userStats.MaxStreak,
+			AverageGuesses:    userStats.GetAverageGuesses(),
+			GuessDistribution: userStats.GuessDistribution,
+			LastWordDate:      userStats.LastWordDate,
+			HardMode: hardModeOutput{
+				GamesPlayed:    userStats.HardModeGamesPlayed,
+				GamesWon:       userStats.HardModeGamesWon,
+				WinRate:        userStats.GetHardModeWinRate(),
+				AverageGuesses: userStats.GetHardModeAverageGuesses(),
+			},
+		})
+	}
+
+	wish.Printf(sess, "Statistics for %s\n\n", username)
+	wish.Printf(sess, "Games played:     %d\n", userStats.GamesPlayed)
+	wish.Printf(sess, "Games won:        %d\n", userStats.GamesWon)
+	wish.Printf(sess, "Win rate:         %.1f%%\n", userStats.GetWinRate())
+	wish.Printf(sess, "Current streak:   %d\n", userStats.CurrentStreak)
+	wish.Printf(sess, "Max streak:       %d\n", userStats.MaxStreak)
+	wish.Printf(sess, "Average guesses:  %.2f\n", userStats.GetAverageGuesses())
+
+	if userStats.HardModeGamesPlayed > 0 {
+		wish.Printf(sess, "Hard mode:        %d/%d won, %.2f average guesses\n",
+			userStats.HardModeGamesWon, userStats.HardModeGamesPlayed, userStats.GetHardModeAverageGuesses())
+	}
+
+	wish.Println(sess, "\nGuess distribution:")
+	for i, count := range userStats.GuessDistribution {
+		wish.Printf(sess, "  %d: %d\n", i+1, count)
+	}
+
+	return nil
+}
+
+// runHistory prints the finished games of the user, newest first
+func (s *Server) runHistory(sess ssh.Session, args []string) error {
+	fs := newFlagSet(sess, "history")
+	jsonOutput := fs.Bool("json", false, "print JSON")
+	limit := fs.Int("limit", defaultHistoryLimit, "number of games to list, 0 for all")
+	if positional, err := parseArgs(fs, args); err != nil {
+		return err
+	} else if len(positional) > 0 {
+		return errors.New("usage: ssh <host> history [--limit n] [--json]")
+	}
+
+	username, sshKeyFingerprint := sessionIdentity(sess)
+
+	games, err := s.statsStore.GetGames(username, sshKeyFingerprint)
+	if err != nil {
+		s.config.Logger.Error("Failed to get games", "error", err, "username", username)
+		return errors.New("failed to load your history, please try again later")
+	}
+
+	history := make([]gameOutput, 0, len(games))
+	for i := len(games) - 1; i >= 0; i-- {
+		if *limit > 0 && len(history) == *limit {
+			break
+		}
+
+		game := games[i]
+		history = append(history, gameOutput{
+			Date:       game.WordDate,
+			Mode:       string(game.Mode),
+			Won:        game.Won,
+			Guesses:    game.Guesses,
+			HardMode:   game.HardMode,
+			FinishedAt: game.FinishedAt,
+		})
+	}
+
+	if *jsonOutput {
+		return writeJSON(sess, history)
+	}
+
+	if len(history) == 0 {
+		wish.Println(sess, "You haven't finished any games yet.")
+		return nil
+	}
+
+	for _, game := range history {
+		outcome, score := "lost", fmt.Sprintf("X/%d", engine.MaxGuesses)
+		if game.Won {
+			outcome, score = "won", fmt.Sprintf("%d/%d", game.Guesses, engine.MaxGuesses)
+		}
+
+		if game.HardMode {
+			score += "*"
+		}
+
+		wish.Printf(sess, "%s  %-7s  %-4s  %s\n", game.Date, game.Mode, outcome, score)
+	}
+
+	return nil
+}
+
+// runShare prints the shareable result of the user's daily game for a date
+func (s *Server) runShare(sess ssh.Session, args []string) error {
+	username, sshKeyFingerprint := sessionIdentity(sess)
+	settings := s.loadUserSettings(username, sshKeyFingerprint)
+
+	fs := newFlagSet(sess, "share")
+	jsonOutput := fs.Bool("json", false, "print JSON")
+	ascii := fs.Bool("ascii", settings.ASCIIShare, "use plain characters instead of emoji")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	date := settings.Today()
+	switch len(positional) {
+	case 0:
+	case 1:
+		if _, err := time.Parse(stats.DateFormat, positional[0]); err != nil {
+			return fmt.Errorf("invalid date %q, expected YYYY-MM-DD", positional[0])
+		}
+		date = positional[0]
+	default:
+		return errors.New("usage: ssh <host> share [date] [--ascii] [--json]")
+	}
+
+	games, err := s.statsStore.GetGames(username, sshKeyFingerprint)
+	if err != nil {
+		s.config.Logger.Error("Failed to get games", "error", err, "username", username)
+		return errors.New("failed to load your result, please try again later")
+	}
+
+	var game *stats.Game
+	for i := range games {
+		if games[i].WordDate == date && games[i].GameResult != "" {
+			game = &games[i]
+		}
+	}
+
+	if game == nil {
+		return fmt.Errorf("you have no result to share for %s", date)
+	}
+
+	result, err := engine.ParseResult(game.GameResult)
+	if err != nil {
+		s.config.Logger.Error("Failed to parse game result", "error", err, "username", username, "date", date)
+		return errors.New("failed to load your result, please try again later")
+	}
+
+	number := wordle.PuzzleNumber(date)
+	if word, err := s.statsStore.GetDailyWord(date); err == nil && word != nil && word.Number > 0 {
+		number = word.Number
+	}
+
+	text := result.ShareText(number, *ascii)
+	if *jsonOutput {
+		return writeJSON(sess, shareOutput{
+			Date:         date,
+			PuzzleNumber: number,
+			Score:        result.Score(),
+			Won:          result.Won,
+			Guesses:      len(result.Guesses),
+			HardMode:     result.HardMode,
+			Text:         text,
+		})
+	}
+
+	wish.Println(sess, text)
+	return nil
+}
+
+// runLeaderboard prints one of the global leaderboards
+func (s *Server) runLeaderboard(sess ssh.Session, args []string) error {
+	fs := newFlagSet(sess, "leaderboard")
+	jsonOutput := fs.Bool("json", false, "print JSON")
+	limit := fs.Int("limit", defaultLeaderboardLimit, "number of entries to list, at least 1")
+	positional, err := parseArgs(fs, args)
+	if err != nil {
+		return err
+	}
+
+	board := "today"
+	switch {
+	case len(positional) > 1 || *limit < 1:
+		return errors.New("usage: ssh <host> leaderboard [today|win-rate|streak|max-streak] [--limit n] [--json]")
+	case len(positional) == 1:
+		board = positional[0]
+	}
+
+	username, sshKeyFingerprint := sessionIdentity(sess)
+	today := s.loadUserSettings(username, sshKeyFingerprint).Today()
+
+	var entries []stats.LeaderboardEntry
+	switch board {
+	case "today":
+		entries, err = s.statsStore.DailyLeaderboard(today, *limit)
+	case "win-rate":
+		entries, err = s.statsStore.WinRateLeaderboard(*limit)
+	case "streak":
+		entries, err = s.statsStore.StreakLeaderboard(false, *limit)
+	case "max-streak":
+		entries, err = s.statsStore.StreakLeaderboard(true, *limit)
+	default:
+		return fmt.Errorf("unknown leaderboard %q, expected today, win-rate, streak or max-streak", board)
+	}
+
+	if err != nil {
+		s.config.Logger.Error("Failed to get leaderboard", "error", err, "board", board)
+		return errors.New("failed to load the leaderboard, please try again later")
+	}
+
+	if *jsonOutput {
+		output := leaderboardOutput{Board: board, Date: today, Entries: make([]any, 0, len(entries))}
+		for _, entry := range entries {
+			output.Entries = append(output.Entries, leaderboardEntryJSON(board, entry))
+		}
+
+		return writeJSON(sess, output)
+	}
+
+	if len(entries) == 0 {
+		wish.Println(sess, "Nobody is on this leaderboard yet.")
+		return nil
+	}
+
+	for _, entry := range entries {
+		var value string
+		switch board {
+		case "today":
+			value = fmt.Sprintf("%d/%d", entry.Guesses, engine.MaxGuesses)
+			if entry.HardMode {
+				value += "*"
+			}
+			if entry.SolveTime > 0 {
+				value += "  " + entry.SolveTime.Round(time.Second).String()
+			}
+		case "win-rate":
+			value = fmt.Sprintf("%5.1f%%  %d/%d", entry.WinRate(), entry.GamesWon, entry.GamesPlayed)
+		default:
+			value = fmt.Sprintf("current %3d  max %3d", entry.CurrentStreak, entry.MaxStreak)
+		}
+
+		wish.Printf(sess, "%3d. %-20s %s\n", entry.Rank, entry.Username, value)
+	}
+
+	return nil
+}
+
+// leaderboardEntryJSON converts an entry to its JSON output for a board
+func leaderboardEntryJSON(board string, entry stats.LeaderboardEntry) any {
+	rank := rankOutput{Rank: entry.Rank, Username: entry.Username}
+
+	switch board {
+	case "today":
+		output := dailyEntryOutput{rankOutput: rank, Guesses: entry.Guesses, HardMode: entry.HardMode}
+		if entry.SolveTime > 0 {
+			seconds := int(entry.SolveTime.Seconds())
+			output.SolveSeconds = &seconds
+		}
+		return output
+	case "win-rate":
+		return winRateEntryOutput{rankOutput: rank, GamesPlayed: entry.GamesPlayed, GamesWon: entry.GamesWon, WinRate: entry.WinRate()}
+	default:
+		return streakEntryOutput{rankOutput: rank, CurrentStreak: entry.CurrentStreak, MaxStreak: entry.MaxStreak}
+	}
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"context"
+	"io"
+	"sync/atomic"
+	"time"
+)
+
+// timeoutCheckInterval is how often session timeouts are checked, it is also
+// the step of the warning countdown
+const timeoutCheckInterval = time.Second
+
+// timeoutGrace is how long a timed out session may take to save its game
+// before it is closed
+const timeoutGrace = 2 * time.Second
+
+// sessionTimeout disconnects a session after a period without input or once
+// it has been open for too long, warning it shortly before
+type sessionTimeout struct {
+	idle    time.Duration
+	warning time.Duration
+	max     time.Duration
+
+	start        time.Time
+	lastActivity atomic.Int64 // Unix nanoseconds of the last input
+}
+
+// newSessionTimeout creates the timeout of a session starting now
+func (s *Server) newSessionTimeout() *sessionTimeout {
+	t := &sessionTimeout{
+		idle:    s.config.IdleTimeout,
+		warning: s.config.IdleWarning,
+		max:     s.config.MaxSessionDuration,
+		start:   time.Now(),
+	}
+
+	t.lastActivity.Store(t.start.UnixNano())
+	return t
+}
+
+// Enabled returns whether the session can time out at all
+func (t *sessionTimeout) Enabled() bool {
+	return t.idle > 0 || t.max > 0
+}
+
+// Reader wraps the input of a session, every read counts as activity
+func (t *sessionTimeout) Reader(r io.Reader) io.Reader {
+	return activityReader{reader: r, timeout: t}
+}
+
+// Run calls warn with the time left and whether the session is idle (as
+// opposed to open too long) every second of the warning period, warn(0) once
+// the session is active again, and expire when it times out. It returns when
+// the context is done or the session expired.
+func (t *sessionTimeout) Run(ctx context.Context, warn func(remaining time.Duration, idle bool), expire func(idle bool)) {
+	if !t.Enabled() {
+		return
+	}
+
+	ticker := time.NewTicker(timeoutCheckInterval)
+	defer ticker.Stop()
+
+	warned := false
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			remaining, idle := t.remaining(now)
+
+			switch {
+			case remaining <= 0:
+				expire(idle)
+				return
+			case remaining <= t.warning:
+				warn(remaining, idle)
+				warned = true
+			case warned:
+				warn(0, idle)
+				warned = false
+			}
+		}
+	}
+}
+
+// remaining returns the time until the session times out and whether it is the idle timeout
+func (t *sessionTimeout) remaining(now time.Time) (time.Duration, bool) {
+	var idleLeft, maxLeft time.Duration
+	if t.idle > 0 {
+		idleLeft = time.Unix(0, t.lastActivity.Load()).Add(t.idle).Sub(now)
+	}
+
+	if t.max > 0 {
+		maxLeft = t.start.Add(t.max).Sub(now)
+	}
+
+	switch {
+	case t.max <= 0:
+		return max(idleLeft, 0), true
+	case t.idle <= 0 || maxLeft < idleLeft:
+		return max(maxLeft, 0), false
+	default:
+		return max(idleLeft, 0), true
+	}
+}
+
+// activityReader records the time of every read with data
+type activityReader struct {
+	reader  io.Reader
+	timeout *sessionTimeout
+}
+
+func (r activityReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if n > 0 {
+		r.timeout.lastActivity.Store(time.Now().UnixNano())
+	}
+
+	return n, err
+}
@@ -0,0 +1,358 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+	"github.com/mattn/go-sqlite3"
+)
+
+// AccessList is the list an access rule belongs to
+type AccessList string
+
+const (
+	// AccessBlock rules reject matching connections
+	AccessBlock AccessList = "block"
+	// AccessAllow rules admit matching connections even if a block rule of
+	// the same identity matches, in allowlist mode only they are admitted
+	AccessAllow AccessList = "allow"
+)
+
+// AccessRuleKind is what an access rule matches against
+type AccessRuleKind string
+
+const (
+	// RuleUsername matches a username exactly
+	RuleUsername AccessRuleKind = "username"
+	// RuleGlob matches usernames with a glob pattern like "admin*"
+	RuleGlob AccessRuleKind = "glob"
+	// RuleRegex matches usernames with a regular expression, which must match the whole username
+	RuleRegex AccessRuleKind = "regex"
+	// RuleKey matches an SSH key fingerprint like "SHA256:..."
+	RuleKey AccessRuleKind = "key"
+	// RuleCIDR matches remote addresses in a CIDR range, or a single address
+	RuleCIDR AccessRuleKind = "cidr"
+)
+
+// identity returns what part of a connection a rule kind identifies, the
+// username rule kinds all identify the username
+func (kind AccessRuleKind) identity() AccessRuleKind {
+	switch kind {
+	case RuleGlob, RuleRegex:
+		return RuleUsername
+	default:
+		return kind
+	}
+}
+
+var (
+	// ErrAccessRuleNotFound is returned when removing an unknown access rule
+	ErrAccessRuleNotFound = errors.New("access rule not found")
+	// ErrAccessRuleExists is returned when adding a rule that is already on the list
+	ErrAccessRuleExists = errors.New("access rule already exists")
+)
+
+// defaultBlockedUsernames are blocked in new databases, they are commonly
+// tried by scanners and bots
+var defaultBlockedUsernames = []string{
+	"anonymous", "root", "toor", "admin", "user", "guest", "test", "demo",
+	"ubuntu", "debian", "centos", "fedora", "oracle", "pi", "vagrant",
+	"default", "1234", "ftp", "nginx", "ubnt",
+}
+
+// AccessRule blocks or allows connections matching a username, pattern,
+// SSH key fingerprint or address range
+type AccessRule struct {
+	ID        int64
+	List      AccessList
+	Kind      AccessRuleKind
+	Value     string
+	Reason    string
+	ExpiresAt time.Time // Zero if the rule never expires
+	CreatedAt time.Time
+}
+
+// Expired returns whether the rule has expired at the given time
+func (rule *AccessRule) Expired(now time.Time) bool {
+	return !rule.ExpiresAt.IsZero() && !now.Before(rule.ExpiresAt)
+}
+
+// AccessRequest is a connection checked against the access rules
+type AccessRequest struct {
+	Username          string
+	SSHKeyFingerprint string // Empty if the client did not offer a key
+	IP                net.IP
+}
+
+// AccessRules are the unexpired access rules, compiled for matching
+type AccessRules struct {
+	block []accessMatcher
+	allow []accessMatcher
+}
+
+// accessMatcher is a compiled access rule
+type accessMatcher struct {
+	rule  AccessRule
+	regex *regexp.Regexp
+	cidr  *net.IPNet
+}
+
+// Check decides whether a connection is admitted and returns the rule that
+// decided it, which is nil if no rule matched. An allow rule only overrides
+// block rules of the same identity: allowing a username does not admit it
+// from a blocked address range or with a blocked key, as anyone can connect
+// with any username. In allowlist mode a connection must match an allow rule.
+func (rules *AccessRules) Check(request AccessRequest, allowlistOnly bool) (bool, *AccessRule) {
+	var allowedBy *AccessRule
+	allowed := make(map[AccessRuleKind]bool)
+
+	for _, m := range rules.allow {
+		if m.matches(request) {
+			if allowedBy == nil {
+				allowedBy = &m.rule
+			}
+			allowed[m.rule.Kind.identity()] = true
+		}
+	}
+
+	for _, m := range rules.block {
+		if m.matches(request) && !allowed[m.rule.Kind.identity()] {
+			return false, &m.rule
+		}
+	}
+
+	if allowedBy != nil {
+		return true, allowedBy
+	}
+
+	return !allowlistOnly, nil
+}
+
+// matches returns whether the rule applies to a connection
+func (m *accessMatcher) matches(request AccessRequest) bool {
+	switch m.rule.Kind {
+	case RuleUsername:
+		return request.Username == m.rule.Value
+	case RuleGlob:
+		matched, _ := path.Match(m.rule.Value, request.Username)
+		return matched
+	case RuleRegex:
+		return m.regex.MatchString(request.Username)
+	case RuleKey:
+		return request.SSHKeyFingerprint != "" && request.SSHKeyFingerprint == m.rule.Value
+	case RuleCIDR:
+		return request.IP != nil && m.cidr.Contains(request.IP)
+	default:
+		return false
+	}
+}
+
+// compileAccessRule validates a rule and prepares it for matching
+func compileAccessRule(rule AccessRule) (accessMatcher, error) {
+	m := accessMatcher{rule: rule}
+
+	if rule.List != AccessBlock && rule.List != AccessAllow {
+		return m, fmt.Errorf("unknown access list %q, expected block or allow", rule.List)
+	}
+
+	if rule.Value == "" {
+		return m, errors.New("access rule value must not be empty")
+	}
+
+	switch rule.Kind {
+	case RuleUsername, RuleKey:
+	case RuleGlob:
+		if _, err := path.Match(rule.Value, ""); err != nil {
+			return m, fmt.Errorf("invalid glob pattern %q: %w", rule.Value, err)
+		}
+	case RuleRegex:
+		if _, err := regexp.Compile(rule.Value); err != nil {
+			return m, fmt.Errorf("invalid regular expression %q: %w", rule.Value, err)
+		}
+		m.regex = regexp.MustCompile("^(?:" + rule.Value + ")$")
+	case RuleCIDR:
+		_, cidr, err := net.ParseCIDR(rule.Value)
+		if err != nil {
+			return m, fmt.Errorf("invalid CIDR range %q: %w", rule.Value, err)
+		}
+		m.cidr = cidr
+	default:
+		return m, fmt.Errorf("unknown access rule kind %q, expected username, glob, regex, key or cidr", rule.Kind)
+	}
+
+	return m, nil
+}
+
+// normalizeCIDR turns a single address into a range containing only it
+func normalizeCIDR(value string) string {
+	if strings.Contains(value, "/") {
+		return value
+	}
+
+	ip := net.ParseIP(value)
+	if ip == nil {
+		return value
+	}
+
+	if ip.To4() != nil {
+		return ip.String() + "/32"
+	}
+
+	return ip.String() + "/128"
+}
+
+// AddAccessRule validates and stores an access rule
+func (s *Store) AddAccessRule(rule AccessRule) (*AccessRule, error) {
+	defer metrics.ObserveQuery("add_access_rule")()
+
+	rule.Value = strings.TrimSpace(rule.Value)
+	if rule.Kind == RuleCIDR {
+		rule.Value = normalizeCIDR(rule.Value)
+	}
+
+	if _, err := compileAccessRule(rule); err != nil {
+		return nil, err
+	}
+
+	var expiresAt any
+	if !rule.ExpiresAt.IsZero() {
+		expiresAt = rule.ExpiresAt.UTC()
+	}
+
+	result, err := s.db.Exec(`
+		INSERT INTO access_rules (list, kind, value, reason, expires_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, string(rule.List), string(rule.Kind), rule.Value, rule.Reason, expiresAt)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return nil, ErrAccessRuleExists
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to add access rule: %w", err)
+	}
+
+	rule.ID, err = result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access rule id: %w", err)
+	}
+
+	s.logger.Info("Added access rule", "id", rule.ID, "list", rule.List, "kind", rule.Kind, "value", rule.Value)
+	return &rule, nil
+}
+
+// RemoveAccessRule deletes an access rule
+func (s *Store) RemoveAccessRule(id int64) error {
+	defer metrics.ObserveQuery("remove_access_rule")()
+
+	result, err := s.db.Exec(`DELETE FROM access_rules WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to remove access rule: %w", err)
+	}
+
+	removed, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if removed == 0 {
+		return ErrAccessRuleNotFound
+	}
+
+	s.logger.Info("Removed access rule", "id", id)
+	return nil
+}
+
+// ListAccessRules returns every access rule including expired ones, ordered by list and id
+func (s *Store) ListAccessRules() ([]AccessRule, error) {
+	defer metrics.ObserveQuery("list_access_rules")()
+
+	rows, err := s.db.Query(`
+		SELECT id, list, kind, value, reason, expires_at, created_at
+		FROM access_rules
+		ORDER BY list, id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list access rules: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var rules []AccessRule
+	for rows.Next() {
+		var rule AccessRule
+		var list, kind string
+		var expiresAt sql.NullTime
+
+		if err := rows.Scan(&rule.ID, &list, &kind, &rule.Value, &rule.Reason, &expiresAt, &rule.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan access rule: %w", err)
+		}
+
+		rule.List = AccessList(list)
+		rule.Kind = AccessRuleKind(kind)
+		if expiresAt.Valid {
+			rule.ExpiresAt = expiresAt.Time
+		}
+
+		rules = append(rules, rule)
+	}
+
+	return rules, rows.Err()
+}
+
+// LoadAccessRules returns the unexpired access rules compiled for matching,
+// invalid stored rules are skipped
+func (s *Store) LoadAccessRules() (*AccessRules, error) {
+	rules, err := s.ListAccessRules()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	compiled := &AccessRules{}
+
+	for _, rule := range rules {
+		if rule.Expired(now) {
+			continue
+		}
+
+		m, err := compileAccessRule(rule)
+		if err != nil {
+			s.logger.Warn("Skipping invalid access rule", "id", rule.ID, "error", err)
+			continue
+		}
+
+		if rule.List == AccessAllow {
+			compiled.allow = append(compiled.allow, m)
+		} else {
+			compiled.block = append(compiled.block, m)
+		}
+	}
+
+	return compiled, nil
+}
+
+// seedAccessRules blocks the usernames of the former compiled-in blacklist
+func (s *Store) seedAccessRules(tx *sql.Tx) error {
+	for _, username := range defaultBlockedUsernames {
+		_, err := tx.Exec(`
+			INSERT OR IGNORE INTO access_rules (list, kind, value, reason)
+			VALUES (?, ?, ?, ?)
+		`, string(AccessBlock), string(RuleUsername), username, "default blacklist")
+		if err != nil {
+			return fmt.Errorf("failed to seed access rules: %w", err)
+		}
+	}
+
+	return nil
+}
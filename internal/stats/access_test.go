@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"net"
+	"testing"
+)
+
+// testAccessRules compiles rules for matching, the rule IDs are their positions
+func testAccessRules(t *testing.T, rules ...AccessRule) *AccessRules {
+	t.Helper()
+
+	compiled := &AccessRules{}
+	for i, rule := range rules {
+		rule.ID = int64(i + 1)
+		if rule.Kind == RuleCIDR {
+			rule.Value = normalizeCIDR(rule.Value)
+		}
+
+		m, err := compileAccessRule(rule)
+		if err != nil {
+			t.Fatalf("compileAccessRule(%v) error = %v", rule, err)
+		}
+
+		if rule.List == AccessAllow {
+			compiled.allow = append(compiled.allow, m)
+		} else {
+			compiled.block = append(compiled.block, m)
+		}
+	}
+
+	return compiled
+}
+
+func TestAccessRulesCheck(t *testing.T) {
+	rules := testAccessRules(t,
+		AccessRule{List: AccessBlock, Kind: RuleCIDR, Value: "10.0.0.0/8"},
+		AccessRule{List: AccessBlock, Kind: RuleKey, Value: "SHA256:stolen"},
+		AccessRule{List: AccessBlock, Kind: RuleGlob, Value: "bot*"},
+		AccessRule{List: AccessAllow, Kind: RuleUsername, Value: "alice"},
+		AccessRule{List: AccessAllow, Kind: RuleUsername, Value: "botanist"},
+		AccessRule{List: AccessAllow, Kind: RuleCIDR, Value: "10.1.2.3"},
+	)
+
+	tests := []struct {
+		name     string
+		request  AccessRequest
+		allowed  bool
+		decision int64 // ID of the deciding rule, 0 if none
+	}{
+		{"no rule matches", AccessRequest{Username: "carol", IP: net.ParseIP("192.0.2.1")}, true, 0},
+		{"allowed username", AccessRequest{Username: "alice", IP: net.ParseIP("192.0.2.1")}, true, 4},
+		{"allowed username from a blocked range", AccessRequest{Username: "alice", IP: net.ParseIP("10.0.0.1")}, false, 1},
+		{"allowed username with a blocked key", AccessRequest{Username: "alice", SSHKeyFingerprint: "SHA256:stolen"}, false, 2},
+		{"allowed username overrides a username pattern", AccessRequest{Username: "botanist"}, true, 5},
+		{"blocked username pattern", AccessRequest{Username: "bot1"}, false, 3},
+		{"allowed address inside a blocked range", AccessRequest{Username: "carol", IP: net.ParseIP("10.1.2.3")}, true, 6},
+		{"allowed address with a blocked username", AccessRequest{Username: "bot1", IP: net.ParseIP("10.1.2.3")}, false, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			allowed, rule := rules.Check(tt.request, false)
+			if allowed != tt.allowed {
+				t.Errorf("Check() allowed = %t, want %t", allowed, tt.allowed)
+			}
+
+			var decision int64
+			if rule != nil {
+				decision = rule.ID
+			}
+
+			if decision != tt.decision {
+				t.Errorf("Check() decided by rule %d, want %d", decision, tt.decision)
+			}
+		})
+	}
+}
+
+func TestAccessRulesCheckAllowlistOnly(t *testing.T) {
+	rules := testAccessRules(t,
+		AccessRule{List: AccessBlock, Kind: RuleCIDR, Value: "10.0.0.0/8"},
+		AccessRule{List: AccessAllow, Kind: RuleUsername, Value: "alice"},
+	)
+
+	if allowed, _ := rules.Check(AccessRequest{Username: "alice", IP: net.ParseIP("192.0.2.1")}, true); !allowed {
+		t.Error("Check() blocked an allowed username in allowlist mode")
+	}
+
+	if allowed, _ := rules.Check(AccessRequest{Username: "alice", IP: net.ParseIP("10.0.0.1")}, true); allowed {
+		t.Error("Check() admitted an allowed username from a blocked range in allowlist mode")
+	}
+
+	if allowed, rule := rules.Check(AccessRequest{Username: "carol", IP: net.ParseIP("192.0.2.1")}, true); allowed || rule != nil {
+		t.Errorf("Check() = %t, %v for a connection without allow rule in allowlist mode, want blocked by no rule", allowed, rule)
+	}
+}
@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+)
+
+// DailyWord is a puzzle solution that has been served by the server
+type DailyWord struct {
+	Date      string // YYYY-MM-DD
+	Solution  string
+	Source    string
+	Number    int // Puzzle number as shown when sharing
+	FetchedAt time.Time
+}
+
+// GetDailyWord retrieves the stored solution for a date, returns nil if none is stored
+func (s *Store) GetDailyWord(date string) (*DailyWord, error) {
+	defer metrics.ObserveQuery("get_daily_word")()
+
+	query := `SELECT word_date, solution, source, COALESCE(puzzle_number, 0), fetched_at FROM daily_words WHERE word_date = ?`
+
+	var word DailyWord
+	err := s.db.QueryRow(query, date).Scan(&word.Date, &word.Solution, &word.Source, &word.Number, &word.FetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily word: %w", err)
+	}
+
+	return &word, nil
+}
+
+// SaveDailyWord stores the solution for a date and returns the stored word.
+// The first solution stored for a date is kept, so the word never changes once served.
+func (s *Store) SaveDailyWord(word DailyWord) (*DailyWord, error) {
+	defer metrics.ObserveQuery("save_daily_word")()
+
+	if word.FetchedAt.IsZero() {
+		word.FetchedAt = time.Now()
+	}
+
+	query := `
+		INSERT INTO daily_words (word_date, solution, source, puzzle_number, fetched_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(word_date) DO NOTHING
+	`
+
+	if _, err := s.db.Exec(query, word.Date, word.Solution, word.Source, word.Number, word.FetchedAt); err != nil {
+		return nil, fmt.Errorf("failed to save daily word: %w", err)
+	}
+
+	stored, err := s.GetDailyWord(word.Date)
+	if err != nil {
+		return nil, err
+	}
+
+	if stored == nil {
+		return nil, fmt.Errorf("daily word for %s missing after save", word.Date)
+	}
+
+	if stored.Solution != word.Solution {
+		s.logger.Warn("Ignoring changed solution for stored date",
+			"date", word.Date,
+			"stored_source", stored.Source,
+			"new_source", word.Source,
+		)
+	}
+
+	return stored, nil
+}
+
+// ListDailyWords returns the most recent stored solutions, newest first
+func (s *Store) ListDailyWords(limit int) ([]DailyWord, error) {
+	defer metrics.ObserveQuery("list_daily_words")()
+
+	query := `SELECT word_date, solution, source, COALESCE(puzzle_number, 0), fetched_at FROM daily_words ORDER BY word_date DESC LIMIT ?`
+
+	rows, err := s.db.Query(query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list daily words: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var words []DailyWord
+	for rows.Next() {
+		var word DailyWord
+		if err := rows.Scan(&word.Date, &word.Solution, &word.Source, &word.Number, &word.FetchedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan daily word: %w", err)
+		}
+
+		words = append(words, word)
+	}
+
+	return words, rows.Err()
+}
+
+// RandomDailyWord returns a random stored solution from before a date, or nil
+// if none is stored
+func (s *Store) RandomDailyWord(before string) (*DailyWord, error) {
+	defer metrics.ObserveQuery("random_daily_word")()
+
+	query := `SELECT word_date, solution, source, COALESCE(puzzle_number, 0), fetched_at FROM daily_words WHERE word_date < ? ORDER BY RANDOM() LIMIT 1`
+
+	var word DailyWord
+	err := s.db.QueryRow(query, before).Scan(&word.Date, &word.Solution, &word.Source, &word.Number, &word.FetchedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get random daily word: %w", err)
+	}
+
+	return &word, nil
+}
@@ -0,0 +1,152 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+	"github.com/mattn/go-sqlite3"
+)
+
+// ErrAlreadyRecorded is returned when a user already has a result for a puzzle date
+var ErrAlreadyRecorded = errors.New("game already recorded for this puzzle date")
+
+// GameMode distinguishes the daily puzzle from replays of past puzzles
+type GameMode string
+
+const (
+	GameModeDaily   GameMode = "daily"
+	GameModeArchive GameMode = "archive"
+)
+
+// Game is a single finished game
+type Game struct {
+	Username          string
+	SSHKeyFingerprint string
+	WordDate          string
+	Mode              GameMode
+	HardMode          bool
+	Won               bool
+	Guesses           int
+	GameResult        string // JSON-encoded guesses with feedback
+	StartedAt         time.Time
+	FinishedAt        time.Time
+	Imported          bool // Seeded from last_game_result, already counted in the baseline
+}
+
+// insertGame adds a finished game to the history
+func (s *Store) insertGame(q querier, game *Game) error {
+	query := `
+		INSERT INTO games (
+			username, ssh_key_fingerprint, word_date, mode, hard_mode, won, guesses, game_result,
+			started_at, finished_at, imported
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	var startedAt sql.NullTime
+	if !game.StartedAt.IsZero() {
+		startedAt = sql.NullTime{Time: game.StartedAt, Valid: true}
+	}
+
+	_, err := q.Exec(query,
+		game.Username,
+		game.SSHKeyFingerprint,
+		game.WordDate,
+		string(game.Mode),
+		game.HardMode,
+		game.Won,
+		game.Guesses,
+		game.GameResult,
+		startedAt,
+		game.FinishedAt,
+		game.Imported,
+	)
+
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+		return ErrAlreadyRecorded
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to save game: %w", err)
+	}
+
+	return nil
+}
+
+// GetGames returns all finished games of a user, oldest first
+func (s *Store) GetGames(username string, sshKeyFingerprint string) ([]Game, error) {
+	defer metrics.ObserveQuery("get_games")()
+
+	return s.getGames(s.db, username, sshKeyFingerprint)
+}
+
+// getGames returns all finished games of a user using the given querier
+func (s *Store) getGames(q querier, username string, sshKeyFingerprint string) ([]Game, error) {
+	query := `
+		SELECT username, ssh_key_fingerprint, word_date, mode, hard_mode, won, guesses, COALESCE(game_result, ''),
+		       started_at, finished_at, imported
+		FROM games
+		WHERE username = ? AND ssh_key_fingerprint = ?
+		ORDER BY word_date, finished_at
+	`
+
+	rows, err := q.Query(query, username, sshKeyFingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get games: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var games []Game
+	for rows.Next() {
+		var game Game
+		var mode string
+		var startedAt sql.NullTime
+
+		if err := rows.Scan(
+			&game.Username,
+			&game.SSHKeyFingerprint,
+			&game.WordDate,
+			&mode,
+			&game.HardMode,
+			&game.Won,
+			&game.Guesses,
+			&game.GameResult,
+			&startedAt,
+			&game.FinishedAt,
+			&game.Imported,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan game: %w", err)
+		}
+
+		game.Mode = GameMode(mode)
+		if startedAt.Valid {
+			game.StartedAt = startedAt.Time
+		}
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+// GetPlayedDates returns the puzzle dates a user has finished, mapped to whether they won
+func (s *Store) GetPlayedDates(username string, sshKeyFingerprint string) (map[string]bool, error) {
+	defer metrics.ObserveQuery("get_played_dates")()
+
+	games, err := s.GetGames(username, sshKeyFingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	played := make(map[string]bool, len(games))
+	for _, game := range games {
+		played[game.WordDate] = played[game.WordDate] || game.Won
+	}
+
+	return played, nil
+}
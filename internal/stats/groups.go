@@ -0,0 +1,462 @@
+package stats
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+	"github.com/mattn/go-sqlite3"
+)
+
+const (
+	// MaxGroupNameLength is the maximum length of a group name
+	MaxGroupNameLength = 32
+
+	// inviteCodeLength is the number of characters of an invite code
+	inviteCodeLength = 8
+	// inviteCodeAlphabet leaves out characters that are easily confused
+	inviteCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+var (
+	// ErrGroupNotFound is returned for unknown groups and invite codes
+	ErrGroupNotFound = errors.New("group not found")
+	// ErrNotGroupOwner is returned when a member tries an owner-only action
+	ErrNotGroupOwner = errors.New("only the group owner can do this")
+	// ErrRemoveGroupOwner is returned when the owner tries to remove themselves
+	ErrRemoveGroupOwner = errors.New("the owner can't be removed from their group")
+	// ErrInvalidGroupName is returned for empty or overly long group names
+	ErrInvalidGroupName = fmt.Errorf("group name must be 1 to %d characters", MaxGroupNameLength)
+)
+
+// Group is a private group of users with its own leaderboards
+type Group struct {
+	ID                     int64
+	Name                   string
+	InviteCode             string
+	OwnerUsername          string
+	OwnerSSHKeyFingerprint string
+	CreatedAt              time.Time
+	MemberCount            int
+}
+
+// IsOwner returns whether the given user owns the group
+func (group *Group) IsOwner(username string, sshKeyFingerprint string) bool {
+	return group.OwnerUsername == username && group.OwnerSSHKeyFingerprint == sshKeyFingerprint
+}
+
+// GroupMember is a member of a group
+type GroupMember struct {
+	Username          string
+	SSHKeyFingerprint string
+	JoinedAt          time.Time
+}
+
+// CreateGroup creates a group owned by the given user, who becomes its first member
+func (s *Store) CreateGroup(name string, username string, sshKeyFingerprint string) (*Group, error) {
+	defer metrics.ObserveQuery("create_group")()
+
+	name = strings.TrimSpace(name)
+	if name == "" || len([]rune(name)) > MaxGroupNameLength {
+		return nil, ErrInvalidGroupName
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	var groupID int64
+	err = withUniqueInviteCode(func(code string) error {
+		result, err := tx.Exec(`
+			INSERT INTO user_groups (name, invite_code, owner_username, owner_ssh_key_fingerprint)
+			VALUES (?, ?, ?, ?)
+		`, name, code, username, sshKeyFingerprint)
+		if err != nil {
+			return err
+		}
+
+		groupID, err = result.LastInsertId()
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+
+	if err := addGroupMember(tx, groupID, username, sshKeyFingerprint); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit group: %w", err)
+	}
+
+	s.logger.Info("Created group", "group_id", groupID, "name", name, "owner", username)
+	return s.GetGroup(groupID)
+}
+
+// JoinGroup adds a user to the group with the given invite code, joining twice has no effect
+func (s *Store) JoinGroup(inviteCode string, username string, sshKeyFingerprint string) (*Group, error) {
+	defer metrics.ObserveQuery("join_group")()
+
+	inviteCode = strings.ToUpper(strings.TrimSpace(inviteCode))
+
+	var groupID int64
+	err := s.db.QueryRow(`SELECT id FROM user_groups WHERE invite_code = ?`, inviteCode).Scan(&groupID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGroupNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to find group: %w", err)
+	}
+
+	if err := addGroupMember(s.db, groupID, username, sshKeyFingerprint); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Joined group", "group_id", groupID, "username", username)
+	return s.GetGroup(groupID)
+}
+
+// addGroupMember adds a user to a group unless they already are a member
+func addGroupMember(q querier, groupID int64, username string, sshKeyFingerprint string) error {
+	query := `
+		INSERT INTO group_members (group_id, username, ssh_key_fingerprint)
+		VALUES (?, ?, ?)
+		ON CONFLICT(group_id, username, ssh_key_fingerprint) DO NOTHING
+	`
+
+	if _, err := q.Exec(query, groupID, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to add group member: %w", err)
+	}
+
+	return nil
+}
+
+// LeaveGroup removes a user from a group. The group is deleted when its owner leaves.
+func (s *Store) LeaveGroup(groupID int64, username string, sshKeyFingerprint string) error {
+	group, err := s.GetGroup(groupID)
+	if err != nil {
+		return err
+	}
+
+	if group.IsOwner(username, sshKeyFingerprint) {
+		return s.deleteGroup(groupID)
+	}
+
+	if err := removeGroupMember(s.db, groupID, username, sshKeyFingerprint); err != nil {
+		return err
+	}
+
+	s.logger.Info("Left group", "group_id", groupID, "username", username)
+	return nil
+}
+
+// RemoveGroupMember removes a member from a group on behalf of its owner
+func (s *Store) RemoveGroupMember(groupID int64, ownerUsername string, ownerSSHKeyFingerprint string, member GroupMember) error {
+	group, err := s.GetGroup(groupID)
+	if err != nil {
+		return err
+	}
+
+	if !group.IsOwner(ownerUsername, ownerSSHKeyFingerprint) {
+		return ErrNotGroupOwner
+	}
+
+	// The owner leaves by deleting the group instead
+	if group.IsOwner(member.Username, member.SSHKeyFingerprint) {
+		return ErrRemoveGroupOwner
+	}
+
+	if err := removeGroupMember(s.db, groupID, member.Username, member.SSHKeyFingerprint); err != nil {
+		return err
+	}
+
+	s.logger.Info("Removed group member", "group_id", groupID, "username", member.Username, "owner", ownerUsername)
+	return nil
+}
+
+// removeGroupMember removes a user from a group
+func removeGroupMember(q querier, groupID int64, username string, sshKeyFingerprint string) error {
+	query := `DELETE FROM group_members WHERE group_id = ? AND username = ? AND ssh_key_fingerprint = ?`
+	if _, err := q.Exec(query, groupID, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+
+	return nil
+}
+
+// RotateInviteCode replaces the invite code of a group, the old code stops working
+func (s *Store) RotateInviteCode(groupID int64, ownerUsername string, ownerSSHKeyFingerprint string) (string, error) {
+	group, err := s.GetGroup(groupID)
+	if err != nil {
+		return "", err
+	}
+
+	if !group.IsOwner(ownerUsername, ownerSSHKeyFingerprint) {
+		return "", ErrNotGroupOwner
+	}
+
+	var inviteCode string
+	err = withUniqueInviteCode(func(code string) error {
+		inviteCode = code
+		_, err := s.db.Exec(`UPDATE user_groups SET invite_code = ? WHERE id = ?`, code, groupID)
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to rotate invite code: %w", err)
+	}
+
+	s.logger.Info("Rotated group invite code", "group_id", groupID, "owner", ownerUsername)
+	return inviteCode, nil
+}
+
+// deleteGroup deletes a group and its memberships
+func (s *Store) deleteGroup(groupID int64) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if _, err := tx.Exec(`DELETE FROM group_members WHERE group_id = ?`, groupID); err != nil {
+		return fmt.Errorf("failed to delete group members: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_groups WHERE id = ?`, groupID); err != nil {
+		return fmt.Errorf("failed to delete group: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit group deletion: %w", err)
+	}
+
+	s.logger.Info("Deleted group", "group_id", groupID)
+	return nil
+}
+
+// GetGroup retrieves a group by ID
+func (s *Store) GetGroup(groupID int64) (*Group, error) {
+	query := `
+		SELECT g.id, g.name, g.invite_code, g.owner_username, g.owner_ssh_key_fingerprint, g.created_at,
+		       (SELECT COUNT(*) FROM group_members m WHERE m.group_id = g.id)
+		FROM user_groups g
+		WHERE g.id = ?
+	`
+
+	group, err := scanGroup(s.db.QueryRow(query, groupID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrGroupNotFound
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group: %w", err)
+	}
+
+	return group, nil
+}
+
+// GetUserGroups returns the groups a user is a member of, ordered by name
+func (s *Store) GetUserGroups(username string, sshKeyFingerprint string) ([]Group, error) {
+	defer metrics.ObserveQuery("get_user_groups")()
+
+	query := `
+		SELECT g.id, g.name, g.invite_code, g.owner_username, g.owner_ssh_key_fingerprint, g.created_at,
+		       (SELECT COUNT(*) FROM group_members m WHERE m.group_id = g.id)
+		FROM user_groups g
+		JOIN group_members gm ON gm.group_id = g.id
+		WHERE gm.username = ? AND gm.ssh_key_fingerprint = ?
+		ORDER BY g.name COLLATE NOCASE, g.id
+	`
+
+	rows, err := s.db.Query(query, username, sshKeyFingerprint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get groups: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var groups []Group
+	for rows.Next() {
+		group, err := scanGroup(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan group: %w", err)
+		}
+
+		groups = append(groups, *group)
+	}
+
+	return groups, rows.Err()
+}
+
+// GetGroupMembers returns the members of a group in the order they joined
+func (s *Store) GetGroupMembers(groupID int64) ([]GroupMember, error) {
+	defer metrics.ObserveQuery("get_group_members")()
+
+	query := `
+		SELECT username, ssh_key_fingerprint, joined_at
+		FROM group_members
+		WHERE group_id = ?
+		ORDER BY joined_at, username
+	`
+
+	rows, err := s.db.Query(query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var members []GroupMember
+	for rows.Next() {
+		var member GroupMember
+		if err := rows.Scan(&member.Username, &member.SSHKeyFingerprint, &member.JoinedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan group member: %w", err)
+		}
+
+		members = append(members, member)
+	}
+
+	return members, rows.Err()
+}
+
+// GetGroupGames returns the daily games the members of a group finished for a puzzle date
+func (s *Store) GetGroupGames(groupID int64, wordDate string) ([]Game, error) {
+	defer metrics.ObserveQuery("get_group_games")()
+
+	query := `
+		SELECT g.username, g.ssh_key_fingerprint, g.won, g.guesses, g.hard_mode, COALESCE(g.game_result, ''), g.finished_at
+		FROM games g
+		JOIN group_members gm
+			ON gm.username = g.username AND gm.ssh_key_fingerprint = g.ssh_key_fingerprint
+		WHERE gm.group_id = ? AND g.word_date = ? AND g.mode = ?
+		ORDER BY g.finished_at
+	`
+
+	rows, err := s.db.Query(query, groupID, wordDate, string(GameModeDaily))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group games: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var games []Game
+	for rows.Next() {
+		game := Game{WordDate: wordDate, Mode: GameModeDaily}
+		if err := rows.Scan(
+			&game.Username,
+			&game.SSHKeyFingerprint,
+			&game.Won,
+			&game.Guesses,
+			&game.HardMode,
+			&game.GameResult,
+			&game.FinishedAt,
+		); err != nil {
+			return nil, fmt.Errorf("failed to scan group game: %w", err)
+		}
+
+		games = append(games, game)
+	}
+
+	return games, rows.Err()
+}
+
+// deleteUserGroups removes a user from all groups and deletes the groups they own
+func deleteUserGroups(q querier, username string, sshKeyFingerprint string) error {
+	owned := `
+		DELETE FROM group_members WHERE group_id IN (
+			SELECT id FROM user_groups WHERE owner_username = ? AND owner_ssh_key_fingerprint = ?
+		)
+	`
+	if _, err := q.Exec(owned, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to delete members of owned groups: %w", err)
+	}
+
+	if _, err := q.Exec(`DELETE FROM user_groups WHERE owner_username = ? AND owner_ssh_key_fingerprint = ?`, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to delete owned groups: %w", err)
+	}
+
+	if _, err := q.Exec(`DELETE FROM group_members WHERE username = ? AND ssh_key_fingerprint = ?`, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to delete group memberships: %w", err)
+	}
+
+	return nil
+}
+
+// withUniqueInviteCode calls fn with fresh invite codes until one doesn't collide
+func withUniqueInviteCode(fn func(code string) error) error {
+	const attempts = 5
+
+	var err error
+	for range attempts {
+		var code string
+		if code, err = generateInviteCode(); err != nil {
+			return err
+		}
+
+		err = fn(code)
+
+		var sqliteErr sqlite3.Error
+		if !errors.As(err, &sqliteErr) || sqliteErr.ExtendedCode != sqlite3.ErrConstraintUnique {
+			return err
+		}
+	}
+
+	return err
+}
+
+// generateInviteCode returns a random invite code
+func generateInviteCode() (string, error) {
+	alphabetSize := big.NewInt(int64(len(inviteCodeAlphabet)))
+
+	code := make([]byte, inviteCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, alphabetSize)
+		if err != nil {
+			return "", fmt.Errorf("failed to generate invite code: %w", err)
+		}
+
+		code[i] = inviteCodeAlphabet[n.Int64()]
+	}
+
+	return string(code), nil
+}
+
+// scanGroup scans a user_groups row with its member count
+func scanGroup(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*Group, error) {
+	var group Group
+	if err := scanner.Scan(
+		&group.ID,
+		&group.Name,
+		&group.InviteCode,
+		&group.OwnerUsername,
+		&group.OwnerSSHKeyFingerprint,
+		&group.CreatedAt,
+		&group.MemberCount,
+	); err != nil {
+		return nil, err
+	}
+
+	return &group, nil
+}
@@ -0,0 +1,330 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
+)
+
+// statsBaseline holds the aggregates of games played before per-game history
+// was recorded. Aggregates derived from the games table are added on top.
+type statsBaseline struct {
+	GamesPlayed       int
+	GamesWon          int
+	GamesLost         int
+	GuessDistribution [6]int
+	TotalGuesses      int
+	CurrentStreak     int
+	MaxStreak         int
+	LastWordDate      string // Daily games up to this date are covered by the streaks
+}
+
+// getBaseline retrieves the baseline for a user, returns an empty baseline if none is stored
+func (s *Store) getBaseline(q querier, username string, sshKeyFingerprint string) (*statsBaseline, error) {
+	query := `
+		SELECT games_played, games_won, games_lost,
+		       guess_dist_1, guess_dist_2, guess_dist_3, guess_dist_4, guess_dist_5, guess_dist_6,
+		       total_guesses, current_streak, max_streak, COALESCE(last_word_date, '')
+		FROM stats_baseline
+		WHERE username = ? AND ssh_key_fingerprint = ?
+	`
+
+	var baseline statsBaseline
+	err := q.QueryRow(query, username, sshKeyFingerprint).Scan(
+		&baseline.GamesPlayed,
+		&baseline.GamesWon,
+		&baseline.GamesLost,
+		&baseline.GuessDistribution[0],
+		&baseline.GuessDistribution[1],
+		&baseline.GuessDistribution[2],
+		&baseline.GuessDistribution[3],
+		&baseline.GuessDistribution[4],
+		&baseline.GuessDistribution[5],
+		&baseline.TotalGuesses,
+		&baseline.CurrentStreak,
+		&baseline.MaxStreak,
+		&baseline.LastWordDate,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return &statsBaseline{}, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stats baseline: %w", err)
+	}
+
+	return &baseline, nil
+}
+
+// computeUserStats derives a user's aggregates from the baseline and the game history
+func computeUserStats(username string, sshKeyFingerprint string, baseline *statsBaseline, games []Game) *UserStats {
+	stats := &UserStats{
+		Username:          username,
+		SSHKeyFingerprint: sshKeyFingerprint,
+		GamesPlayed:       baseline.GamesPlayed,
+		GamesWon:          baseline.GamesWon,
+		GamesLost:         baseline.GamesLost,
+		GuessDistribution: baseline.GuessDistribution,
+		TotalGuesses:      baseline.TotalGuesses,
+		CurrentStreak:     baseline.CurrentStreak,
+		MaxStreak:         baseline.MaxStreak,
+		LastWordDate:      baseline.LastWordDate,
+	}
+
+	// Games are ordered by puzzle date
+	for _, game := range games {
+		if game.FinishedAt.After(stats.LastPlayed) {
+			stats.LastPlayed = game.FinishedAt
+		}
+
+		// Imported games are already counted in the baseline
+		if !game.Imported {
+			stats.GamesPlayed++
+			if game.Won {
+				stats.GamesWon++
+				stats.TotalGuesses += game.Guesses
+				if game.Guesses >= 1 && game.Guesses <= 6 {
+					stats.GuessDistribution[game.Guesses-1]++
+				}
+			} else {
+				stats.GamesLost++
+			}
+		}
+
+		// Archive games leave the daily streak untouched
+		if game.Mode != GameModeDaily {
+			continue
+		}
+
+		if game.WordDate > baseline.LastWordDate && !game.Imported {
+			if !game.Won {
+				stats.CurrentStreak = 0
+			} else if stats.LastWordDate == previousDate(game.WordDate) {
+				stats.CurrentStreak++
+			} else {
+				stats.CurrentStreak = 1
+			}
+
+			stats.MaxStreak = max(stats.MaxStreak, stats.CurrentStreak)
+		}
+
+		if game.WordDate >= stats.LastWordDate {
+			stats.LastWordDate = game.WordDate
+			stats.LastGameResult = game.GameResult
+		}
+	}
+
+	return stats
+}
+
+// RecomputeUserStats rebuilds a user's aggregates from their game history
+func (s *Store) RecomputeUserStats(username string, sshKeyFingerprint string) (*UserStats, error) {
+	return s.recomputeUserStats(s.db, username, sshKeyFingerprint)
+}
+
+// recomputeUserStats rebuilds a user's aggregates using the given querier
+func (s *Store) recomputeUserStats(q querier, username string, sshKeyFingerprint string) (*UserStats, error) {
+	baseline, err := s.getBaseline(q, username, sshKeyFingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	games, err := s.getGames(q, username, sshKeyFingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := computeUserStats(username, sshKeyFingerprint, baseline, games)
+	if err := s.saveUserStats(q, stats); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// recomputeAllUserStats rebuilds the aggregates of every user with game history
+func (s *Store) recomputeAllUserStats(tx *sql.Tx) error {
+	rows, err := tx.Query(`SELECT DISTINCT username, ssh_key_fingerprint FROM games`)
+	if err != nil {
+		return fmt.Errorf("failed to find users to recompute: %w", err)
+	}
+
+	type user struct {
+		username          string
+		sshKeyFingerprint string
+	}
+
+	var users []user
+	for rows.Next() {
+		var u user
+		if err := rows.Scan(&u.username, &u.sshKeyFingerprint); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan user to recompute: %w", err)
+		}
+
+		users = append(users, u)
+	}
+
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to find users to recompute: %w", err)
+	}
+
+	for _, u := range users {
+		if _, err := s.recomputeUserStats(tx, u.username, u.sshKeyFingerprint); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// seedGameHistory moves aggregates of games played before per-game history
+// into the baseline and imports each user's last game from last_game_result
+func (s *Store) seedGameHistory(tx *sql.Tx) error {
+	query := `
+		SELECT username, ssh_key_fingerprint
+		FROM user_stats u
+		WHERE NOT EXISTS (
+			SELECT 1 FROM stats_baseline b
+			WHERE b.username = u.username AND b.ssh_key_fingerprint = u.ssh_key_fingerprint
+		)
+	`
+
+	rows, err := tx.Query(query)
+	if err != nil {
+		return fmt.Errorf("failed to find users to seed: %w", err)
+	}
+
+	type user struct {
+		username          string
+		sshKeyFingerprint string
+	}
+
+	var users []user
+	for rows.Next() {
+		var u user
+		if err := rows.Scan(&u.username, &u.sshKeyFingerprint); err != nil {
+			_ = rows.Close()
+			return fmt.Errorf("failed to scan user to seed: %w", err)
+		}
+
+		users = append(users, u)
+	}
+
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("failed to find users to seed: %w", err)
+	}
+
+	for _, u := range users {
+		if err := s.seedUserHistory(tx, u.username, u.sshKeyFingerprint); err != nil {
+			return err
+		}
+	}
+
+	if len(users) > 0 {
+		s.logger.Info("Seeded game history", "users", len(users))
+	}
+
+	return nil
+}
+
+// seedUserHistory seeds the baseline and imported last game of a single user
+func (s *Store) seedUserHistory(tx *sql.Tx, username string, sshKeyFingerprint string) error {
+	stats, err := s.getUserStats(tx, username, sshKeyFingerprint)
+	if err != nil {
+		return err
+	}
+
+	games, err := s.getGames(tx, username, sshKeyFingerprint)
+	if err != nil {
+		return err
+	}
+
+	// Whatever the recorded games don't explain was played before history existed
+	derived := computeUserStats(username, sshKeyFingerprint, &statsBaseline{}, games)
+	baseline := statsBaseline{
+		GamesPlayed:   max(stats.GamesPlayed-derived.GamesPlayed, 0),
+		GamesWon:      max(stats.GamesWon-derived.GamesWon, 0),
+		GamesLost:     max(stats.GamesLost-derived.GamesLost, 0),
+		TotalGuesses:  max(stats.TotalGuesses-derived.TotalGuesses, 0),
+		CurrentStreak: stats.CurrentStreak,
+		MaxStreak:     stats.MaxStreak,
+		LastWordDate:  stats.LastWordDate,
+	}
+
+	for i := range baseline.GuessDistribution {
+		baseline.GuessDistribution[i] = max(stats.GuessDistribution[i]-derived.GuessDistribution[i], 0)
+	}
+
+	insertBaseline := `
+		INSERT INTO stats_baseline (
+			username, ssh_key_fingerprint, games_played, games_won, games_lost,
+			guess_dist_1, guess_dist_2, guess_dist_3, guess_dist_4, guess_dist_5, guess_dist_6,
+			total_guesses, current_streak, max_streak, last_word_date
+		) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	if _, err := tx.Exec(insertBaseline,
+		username,
+		sshKeyFingerprint,
+		baseline.GamesPlayed,
+		baseline.GamesWon,
+		baseline.GamesLost,
+		baseline.GuessDistribution[0],
+		baseline.GuessDistribution[1],
+		baseline.GuessDistribution[2],
+		baseline.GuessDistribution[3],
+		baseline.GuessDistribution[4],
+		baseline.GuessDistribution[5],
+		baseline.TotalGuesses,
+		baseline.CurrentStreak,
+		baseline.MaxStreak,
+		baseline.LastWordDate,
+	); err != nil {
+		return fmt.Errorf("failed to save stats baseline: %w", err)
+	}
+
+	if imported := importLastGame(stats, games); imported != nil {
+		if err := s.insertGame(tx, imported); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// importLastGame builds a history entry from last_game_result unless that game is already recorded
+func importLastGame(stats *UserStats, games []Game) *Game {
+	if stats.LastGameResult == "" || stats.LastWordDate == "" {
+		return nil
+	}
+
+	for _, game := range games {
+		if game.WordDate == stats.LastWordDate {
+			return nil
+		}
+	}
+
+	result, err := engine.ParseResult(stats.LastGameResult)
+	if err != nil {
+		return nil
+	}
+
+	return &Game{
+		Username:          stats.Username,
+		SSHKeyFingerprint: stats.SSHKeyFingerprint,
+		WordDate:          stats.LastWordDate,
+		Mode:              GameModeDaily,
+		HardMode:          result.HardMode,
+		Won:               result.Won,
+		Guesses:           len(result.Guesses),
+		GameResult:        stats.LastGameResult,
+		FinishedAt:        stats.LastPlayed,
+		Imported:          true,
+	}
+}
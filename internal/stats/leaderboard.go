@@ -0,0 +1,319 @@
+package stats
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+)
+
+// defaultLeaderboardMinGames is the number of games needed to be ranked by win rate
+const defaultLeaderboardMinGames = 10
+
+// LeaderboardConfig holds the operator's leaderboard settings
+type LeaderboardConfig struct {
+	OptIn    bool // Users only appear on leaderboards after opting in
+	MinGames int  // Games needed to be ranked by win rate
+}
+
+// LeaderboardEntry is a single ranked user. Only the fields of the
+// leaderboard it was returned by are set.
+type LeaderboardEntry struct {
+	Rank     int
+	Username string
+
+	// Daily leaderboard
+	Guesses   int
+	HardMode  bool
+	SolveTime time.Duration // Zero if unknown
+
+	// Win rate leaderboard
+	GamesPlayed int
+	GamesWon    int
+
+	// Streak leaderboard
+	CurrentStreak int
+	MaxStreak     int
+}
+
+// WinRate returns the win rate of the entry as a percentage
+func (entry LeaderboardEntry) WinRate() float64 {
+	if entry.GamesPlayed == 0 {
+		return 0
+	}
+
+	return float64(entry.GamesWon) / float64(entry.GamesPlayed) * 100
+}
+
+// SetLeaderboardConfig sets the operator's leaderboard settings
+func (s *Store) SetLeaderboardConfig(config LeaderboardConfig) {
+	if config.MinGames <= 0 {
+		config.MinGames = defaultLeaderboardMinGames
+	}
+
+	s.leaderboard = config
+}
+
+// LeaderboardConfig returns the operator's leaderboard settings
+func (s *Store) LeaderboardConfig() LeaderboardConfig {
+	return s.leaderboard
+}
+
+// leaderboardScope selects the users ranked on a leaderboard: everyone who
+// hasn't opted out, or the members of a group
+type leaderboardScope struct {
+	groupID int64 // Zero for the global leaderboard
+}
+
+// condition returns the SQL condition selecting the users in scope from the
+// table with the given alias, which needs user_settings joined as us
+func (s *Store) condition(scope leaderboardScope, alias string) (string, []any) {
+	if scope.groupID != 0 {
+		condition := `EXISTS (
+			SELECT 1 FROM group_members gm
+			WHERE gm.group_id = ? AND gm.username = ` + alias + `.username AND gm.ssh_key_fingerprint = ` + alias + `.ssh_key_fingerprint
+		)`
+		return condition, []any{scope.groupID}
+	}
+
+	return `COALESCE(us.show_on_leaderboard, ?) = 1`, []any{!s.leaderboard.OptIn}
+}
+
+// minGames returns the number of games needed to be ranked by win rate,
+// groups are small so every member with a game is ranked
+func (s *Store) minGames(scope leaderboardScope) int {
+	if scope.groupID != 0 {
+		return 1
+	}
+
+	return s.leaderboard.MinGames
+}
+
+// DailyLeaderboard ranks the winners of a daily puzzle by guesses, then solve time
+func (s *Store) DailyLeaderboard(wordDate string, limit int) ([]LeaderboardEntry, error) {
+	return s.dailyLeaderboard(leaderboardScope{}, wordDate, limit)
+}
+
+// GroupDailyLeaderboard ranks the members of a group who won a daily puzzle
+func (s *Store) GroupDailyLeaderboard(groupID int64, wordDate string, limit int) ([]LeaderboardEntry, error) {
+	return s.dailyLeaderboard(leaderboardScope{groupID: groupID}, wordDate, limit)
+}
+
+func (s *Store) dailyLeaderboard(scope leaderboardScope, wordDate string, limit int) ([]LeaderboardEntry, error) {
+	defer metrics.ObserveQuery("daily_leaderboard")()
+
+	condition, args := s.condition(scope, "g")
+	query := `
+		SELECT g.username, g.guesses, g.hard_mode, g.started_at, g.finished_at
+		FROM games g
+		LEFT JOIN user_settings us
+			ON us.username = g.username AND us.ssh_key_fingerprint = g.ssh_key_fingerprint
+		WHERE g.word_date = ? AND g.mode = ? AND g.won = 1 AND ` + condition
+
+	rows, err := s.db.Query(query, append([]any{wordDate, string(GameModeDaily)}, args...)...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily leaderboard: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []LeaderboardEntry
+	for rows.Next() {
+		var entry LeaderboardEntry
+		var startedAt sql.NullTime
+		var finishedAt time.Time
+
+		if err := rows.Scan(&entry.Username, &entry.Guesses, &entry.HardMode, &startedAt, &finishedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan daily leaderboard: %w", err)
+		}
+
+		if startedAt.Valid && finishedAt.After(startedAt.Time) {
+			entry.SolveTime = finishedAt.Sub(startedAt.Time)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get daily leaderboard: %w", err)
+	}
+
+	// Solve times are only known for games played since history was recorded
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.Guesses != b.Guesses {
+			return a.Guesses < b.Guesses
+		}
+
+		if (a.SolveTime == 0) != (b.SolveTime == 0) {
+			return b.SolveTime == 0
+		}
+
+		return a.SolveTime < b.SolveTime
+	})
+
+	return rankEntries(entries, limit), nil
+}
+
+// WinRateLeaderboard ranks users with at least the minimum number of games by win rate
+func (s *Store) WinRateLeaderboard(limit int) ([]LeaderboardEntry, error) {
+	return s.winRateLeaderboard(leaderboardScope{}, limit)
+}
+
+// GroupWinRateLeaderboard ranks the members of a group by win rate
+func (s *Store) GroupWinRateLeaderboard(groupID int64, limit int) ([]LeaderboardEntry, error) {
+	return s.winRateLeaderboard(leaderboardScope{groupID: groupID}, limit)
+}
+
+func (s *Store) winRateLeaderboard(scope leaderboardScope, limit int) ([]LeaderboardEntry, error) {
+	defer metrics.ObserveQuery("win_rate_leaderboard")()
+
+	condition, args := s.condition(scope, "s")
+	query := `
+		SELECT s.username, s.games_played, s.games_won
+		FROM user_stats s
+		LEFT JOIN user_settings us
+			ON us.username = s.username AND us.ssh_key_fingerprint = s.ssh_key_fingerprint
+		WHERE s.games_played >= ? AND ` + condition + `
+		ORDER BY CAST(s.games_won AS REAL) / s.games_played DESC, s.games_won DESC
+	`
+
+	args = append([]any{s.minGames(scope)}, args...)
+
+	// Without a positive limit every entry is listed, like on the other leaderboards
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get win rate leaderboard: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var entries []LeaderboardEntry
+	for rows.Next() {
+		var entry LeaderboardEntry
+		if err := rows.Scan(&entry.Username, &entry.GamesPlayed, &entry.GamesWon); err != nil {
+			return nil, fmt.Errorf("failed to scan win rate leaderboard: %w", err)
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get win rate leaderboard: %w", err)
+	}
+
+	return rankEntries(entries, limit), nil
+}
+
+// StreakLeaderboard ranks users by their current streak, or by their max
+// streak if byMax is set. Each current streak is as of the puzzle date in the
+// user's own timezone, so the board is the same for every viewer.
+func (s *Store) StreakLeaderboard(byMax bool, limit int) ([]LeaderboardEntry, error) {
+	return s.streakLeaderboard(leaderboardScope{}, time.Now(), byMax, limit)
+}
+
+// GroupStreakLeaderboard ranks the members of a group by their current or max streak
+func (s *Store) GroupStreakLeaderboard(groupID int64, byMax bool, limit int) ([]LeaderboardEntry, error) {
+	return s.streakLeaderboard(leaderboardScope{groupID: groupID}, time.Now(), byMax, limit)
+}
+
+func (s *Store) streakLeaderboard(scope leaderboardScope, now time.Time, byMax bool, limit int) ([]LeaderboardEntry, error) {
+	defer metrics.ObserveQuery("streak_leaderboard")()
+
+	condition, scopeArgs := s.condition(scope, "s")
+
+	// Whether a stored streak is broken depends on the user's timezone, so the
+	// candidates are filtered and ranked here rather than in SQL
+	where := "s.current_streak > 0"
+	if byMax {
+		where = "s.max_streak > 0"
+	}
+
+	query := `
+		SELECT s.username, s.current_streak, s.max_streak, COALESCE(s.last_word_date, ''), COALESCE(us.timezone, '')
+		FROM user_stats s
+		LEFT JOIN user_settings us
+			ON us.username = s.username AND us.ssh_key_fingerprint = s.ssh_key_fingerprint
+		WHERE ` + where + ` AND ` + condition
+
+	rows, err := s.db.Query(query, scopeArgs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get streak leaderboard: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	todays := make(map[string]string)
+	var entries []LeaderboardEntry
+	for rows.Next() {
+		var entry LeaderboardEntry
+		var userStats UserStats
+		var timezone string
+		if err := rows.Scan(&entry.Username, &userStats.CurrentStreak, &entry.MaxStreak, &userStats.LastWordDate, &timezone); err != nil {
+			return nil, fmt.Errorf("failed to scan streak leaderboard: %w", err)
+		}
+
+		today, ok := todays[timezone]
+		if !ok {
+			settings := UserSettings{Timezone: timezone}
+			today = now.In(settings.Location()).Format(DateFormat)
+			todays[timezone] = today
+		}
+
+		entry.CurrentStreak = userStats.CurrentStreakOn(today)
+		if !byMax && entry.CurrentStreak == 0 {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to get streak leaderboard: %w", err)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if byMax && a.MaxStreak != b.MaxStreak {
+			return a.MaxStreak > b.MaxStreak
+		}
+
+		if a.CurrentStreak != b.CurrentStreak {
+			return a.CurrentStreak > b.CurrentStreak
+		}
+
+		if a.MaxStreak != b.MaxStreak {
+			return a.MaxStreak > b.MaxStreak
+		}
+
+		return a.Username < b.Username
+	})
+
+	return rankEntries(entries, limit), nil
+}
+
+// rankEntries numbers the entries in order and truncates them to the limit
+func rankEntries(entries []LeaderboardEntry, limit int) []LeaderboardEntry {
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+
+	for i := range entries {
+		entries[i].Rank = i + 1
+	}
+
+	return entries
+}
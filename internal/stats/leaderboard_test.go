@@ -0,0 +1,102 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestStreakLeaderboardUsesEachUsersTimezone(t *testing.T) {
+	store := newTestStore(t)
+
+	// At noon UTC it is already the next day in Kiritimati (UTC+14)
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	users := []struct {
+		username     string
+		timezone     string
+		streak       int
+		lastWordDate string
+	}{
+		{"alice", "Pacific/Kiritimati", 5, "2025-01-11"}, // played today in their timezone
+		{"bob", "UTC", 4, "2025-01-09"},                  // played yesterday, today is still open
+		{"carol", "Pacific/Kiritimati", 3, "2025-01-09"}, // missed 2025-01-10 in their timezone
+		{"dave", "UTC", 2, "2025-01-08"},                 // missed 2025-01-09
+	}
+
+	for _, user := range users {
+		fingerprint := "SHA256:" + user.username
+		insert := `INSERT INTO user_stats (username, ssh_key_fingerprint, current_streak, max_streak, last_word_date) VALUES (?, ?, ?, ?, ?)`
+		if _, err := store.db.Exec(insert, user.username, fingerprint, user.streak, user.streak, user.lastWordDate); err != nil {
+			t.Fatalf("failed to insert stats of %s: %v", user.username, err)
+		}
+
+		if err := store.SaveUserSettings(&UserSettings{Username: user.username, SSHKeyFingerprint: fingerprint, Timezone: user.timezone}); err != nil {
+			t.Fatalf("SaveUserSettings() error = %v", err)
+		}
+	}
+
+	entries, err := store.streakLeaderboard(leaderboardScope{}, now, false, 10)
+	if err != nil {
+		t.Fatalf("streakLeaderboard() error = %v", err)
+	}
+
+	var got []string
+	for _, entry := range entries {
+		got = append(got, entry.Username)
+	}
+
+	if len(got) != 2 || got[0] != "alice" || got[1] != "bob" {
+		t.Errorf("current streak leaderboard = %v, want [alice bob]", got)
+	}
+
+	entries, err = store.streakLeaderboard(leaderboardScope{}, now, true, 10)
+	if err != nil {
+		t.Fatalf("streakLeaderboard() error = %v", err)
+	}
+
+	if len(entries) != len(users) || entries[2].Username != "carol" || entries[2].CurrentStreak != 0 || entries[2].MaxStreak != 3 {
+		t.Errorf("max streak leaderboard = %+v, want every user with carol's current streak broken", entries)
+	}
+}
+
+func TestLeaderboardLimits(t *testing.T) {
+	store := newTestStore(t)
+
+	for i, username := range []string{"alice", "bob", "carol"} {
+		game := testGame("2025-01-10")
+		game.Username, game.SSHKeyFingerprint = username, "SHA256:"+username
+		game.Guesses = i + 2
+		if err := store.RecordWin(game); err != nil {
+			t.Fatalf("RecordWin() error = %v", err)
+		}
+
+		update := `UPDATE user_stats SET games_played = 20, games_won = ? WHERE username = ?`
+		if _, err := store.db.Exec(update, 20-i, username); err != nil {
+			t.Fatalf("failed to update stats of %s: %v", username, err)
+		}
+	}
+
+	boards := map[string]func(limit int) ([]LeaderboardEntry, error){
+		"today": func(limit int) ([]LeaderboardEntry, error) {
+			return store.DailyLeaderboard("2025-01-10", limit)
+		},
+		"win rate": store.WinRateLeaderboard,
+		"max streak": func(limit int) ([]LeaderboardEntry, error) {
+			return store.StreakLeaderboard(true, limit)
+		},
+	}
+
+	// Every board lists all entries without a positive limit
+	for name, board := range boards {
+		for limit, want := range map[int]int{0: 3, -1: 3, 2: 2, 5: 3} {
+			entries, err := board(limit)
+			if err != nil {
+				t.Fatalf("%s leaderboard error = %v", name, err)
+			}
+
+			if len(entries) != want {
+				t.Errorf("%s leaderboard with limit %d has %d entries, want %d", name, limit, len(entries), want)
+			}
+		}
+	}
+}
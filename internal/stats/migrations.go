@@ -0,0 +1,160 @@
+package stats
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrSchemaTooNew is returned when the database was migrated by a newer version
+var ErrSchemaTooNew = errors.New("database schema is newer than this binary")
+
+// migration is a single schema change, applied in its own transaction
+type migration struct {
+	version int
+	name    string
+	apply   func(tx *sql.Tx) error
+}
+
+// migrations returns all migrations ordered by version.
+// SQL migrations are embedded from migrations/NNNN_name.sql, data
+// migrations that need Go code are registered here.
+func (s *Store) migrations() ([]migration, error) {
+	files, err := fs.Glob(migrationFiles, "migrations/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+
+	var migrations []migration
+	for _, file := range files {
+		name := strings.TrimSuffix(path.Base(file), ".sql")
+		prefix, label, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("invalid migration file name %q", file)
+		}
+
+		version, err := strconv.Atoi(prefix)
+		if err != nil {
+			return nil, fmt.Errorf("invalid migration version in %q: %w", file, err)
+		}
+
+		script, err := migrationFiles.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", file, err)
+		}
+
+		migrations = append(migrations, migration{
+			version: version,
+			name:    label,
+			apply: func(tx *sql.Tx) error {
+				_, err := tx.Exec(string(script))
+				return err
+			},
+		})
+	}
+
+	migrations = append(migrations,
+		migration{version: 5, name: "seed_game_history", apply: s.seedGameHistory},
+		migration{version: 7, name: "recompute_user_stats", apply: s.recomputeAllUserStats},
+		migration{version: 13, name: "seed_access_rules", apply: s.seedAccessRules},
+	)
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].version < migrations[j].version
+	})
+
+	// Versions must be 1..n without gaps so a missing file is noticed
+	for i, m := range migrations {
+		if m.version != i+1 {
+			return nil, fmt.Errorf("migration %d_%s is out of sequence, expected version %d", m.version, m.name, i+1)
+		}
+	}
+
+	return migrations, nil
+}
+
+// migrate brings the database schema up to date
+func (s *Store) migrate() error {
+	migrations, err := s.migrations()
+	if err != nil {
+		return err
+	}
+
+	query := `
+		CREATE TABLE IF NOT EXISTS schema_version (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME DEFAULT CURRENT_TIMESTAMP
+		)
+	`
+
+	if _, err := s.db.Exec(query); err != nil {
+		return fmt.Errorf("failed to create schema_version table: %w", err)
+	}
+
+	current, err := s.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	latest := len(migrations)
+	if current > latest {
+		return fmt.Errorf("%w: database is at version %d, binary supports up to %d", ErrSchemaTooNew, current, latest)
+	}
+
+	for _, m := range migrations[current:] {
+		if err := s.applyMigration(m); err != nil {
+			return err
+		}
+
+		s.logger.Info("Applied database migration", "version", m.version, "name", m.name)
+	}
+
+	s.logger.Debug("Database schema is up to date", "version", latest)
+	return nil
+}
+
+// applyMigration applies a single migration and records its version
+func (s *Store) applyMigration(m migration) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", m.version, err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	if err := m.apply(tx); err != nil {
+		return fmt.Errorf("failed to apply migration %d_%s: %w", m.version, m.name, err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, name) VALUES (?, ?)`, m.version, m.name); err != nil {
+		return fmt.Errorf("failed to record migration %d: %w", m.version, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d: %w", m.version, err)
+	}
+
+	return nil
+}
+
+// SchemaVersion returns the version of the latest applied migration
+func (s *Store) SchemaVersion() (int, error) {
+	var version int
+	if err := s.db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read schema version: %w", err)
+	}
+
+	return version, nil
+}
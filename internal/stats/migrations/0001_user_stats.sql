@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS user_stats (
+	username TEXT NOT NULL,
+	ssh_key_fingerprint TEXT NOT NULL,
+	games_played INTEGER DEFAULT 0,
+	games_won INTEGER DEFAULT 0,
+	games_lost INTEGER DEFAULT 0,
+	current_streak INTEGER DEFAULT 0,
+	max_streak INTEGER DEFAULT 0,
+	guess_dist_1 INTEGER DEFAULT 0,
+	guess_dist_2 INTEGER DEFAULT 0,
+	guess_dist_3 INTEGER DEFAULT 0,
+	guess_dist_4 INTEGER DEFAULT 0,
+	guess_dist_5 INTEGER DEFAULT 0,
+	guess_dist_6 INTEGER DEFAULT 0,
+	total_guesses INTEGER DEFAULT 0,
+	last_played DATETIME,
+	last_word_date TEXT,
+	last_game_result TEXT,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (username, ssh_key_fingerprint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_last_played ON user_stats(last_played);
+CREATE INDEX IF NOT EXISTS idx_games_won ON user_stats(games_won DESC);
+CREATE INDEX IF NOT EXISTS idx_ssh_key ON user_stats(ssh_key_fingerprint);
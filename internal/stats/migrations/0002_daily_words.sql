@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS daily_words (
+	word_date TEXT PRIMARY KEY,
+	solution TEXT NOT NULL,
+	source TEXT NOT NULL,
+	fetched_at DATETIME NOT NULL
+);
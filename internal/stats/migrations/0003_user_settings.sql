@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS user_settings (
+	username TEXT NOT NULL,
+	ssh_key_fingerprint TEXT NOT NULL,
+	timezone TEXT,
+	hard_mode INTEGER DEFAULT 0,
+	updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (username, ssh_key_fingerprint)
+);
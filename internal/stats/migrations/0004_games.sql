@@ -0,0 +1,36 @@
+CREATE TABLE IF NOT EXISTS games (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	username TEXT NOT NULL,
+	ssh_key_fingerprint TEXT NOT NULL,
+	word_date TEXT NOT NULL,
+	mode TEXT NOT NULL DEFAULT 'daily',
+	hard_mode INTEGER DEFAULT 0,
+	won INTEGER NOT NULL,
+	guesses INTEGER NOT NULL,
+	game_result TEXT,
+	started_at DATETIME,
+	finished_at DATETIME NOT NULL,
+	imported INTEGER DEFAULT 0
+);
+
+CREATE INDEX IF NOT EXISTS idx_games_user ON games(username, ssh_key_fingerprint, word_date);
+
+-- Aggregates of games played before per-game history was recorded
+CREATE TABLE IF NOT EXISTS stats_baseline (
+	username TEXT NOT NULL,
+	ssh_key_fingerprint TEXT NOT NULL,
+	games_played INTEGER DEFAULT 0,
+	games_won INTEGER DEFAULT 0,
+	games_lost INTEGER DEFAULT 0,
+	guess_dist_1 INTEGER DEFAULT 0,
+	guess_dist_2 INTEGER DEFAULT 0,
+	guess_dist_3 INTEGER DEFAULT 0,
+	guess_dist_4 INTEGER DEFAULT 0,
+	guess_dist_5 INTEGER DEFAULT 0,
+	guess_dist_6 INTEGER DEFAULT 0,
+	total_guesses INTEGER DEFAULT 0,
+	current_streak INTEGER DEFAULT 0,
+	max_streak INTEGER DEFAULT 0,
+	last_word_date TEXT,
+	PRIMARY KEY (username, ssh_key_fingerprint)
+);
@@ -0,0 +1,9 @@
+-- Keep only the first recorded game per user and puzzle date
+DELETE FROM games
+WHERE id NOT IN (
+	SELECT MIN(id)
+	FROM games
+	GROUP BY username, ssh_key_fingerprint, word_date
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_games_unique_date ON games(username, ssh_key_fingerprint, word_date);
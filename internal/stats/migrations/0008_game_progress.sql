@@ -0,0 +1,13 @@
+-- Games that have been started but not finished, one per user and puzzle date
+CREATE TABLE IF NOT EXISTS game_progress (
+	username TEXT NOT NULL,
+	ssh_key_fingerprint TEXT NOT NULL,
+	word_date TEXT NOT NULL,
+	mode TEXT NOT NULL DEFAULT 'daily',
+	game_state TEXT NOT NULL,
+	started_at DATETIME NOT NULL,
+	updated_at DATETIME NOT NULL,
+	PRIMARY KEY (username, ssh_key_fingerprint, word_date)
+);
+
+CREATE INDEX IF NOT EXISTS idx_game_progress_date ON game_progress(mode, word_date);
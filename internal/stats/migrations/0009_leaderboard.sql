@@ -0,0 +1,2 @@
+-- NULL follows the operator's default
+ALTER TABLE user_settings ADD COLUMN show_on_leaderboard INTEGER;
@@ -0,0 +1,19 @@
+-- Private groups with their own leaderboards, joined with an invite code
+CREATE TABLE IF NOT EXISTS user_groups (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	name TEXT NOT NULL,
+	invite_code TEXT NOT NULL UNIQUE,
+	owner_username TEXT NOT NULL,
+	owner_ssh_key_fingerprint TEXT NOT NULL,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS group_members (
+	group_id INTEGER NOT NULL,
+	username TEXT NOT NULL,
+	ssh_key_fingerprint TEXT NOT NULL,
+	joined_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	PRIMARY KEY (group_id, username, ssh_key_fingerprint)
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_members_user ON group_members(username, ssh_key_fingerprint);
@@ -0,0 +1,9 @@
+-- Puzzle numbers for shared results, counted in days since the first puzzle on 2021-06-19
+ALTER TABLE daily_words ADD COLUMN puzzle_number INTEGER;
+
+UPDATE daily_words
+SET puzzle_number = CAST(julianday(word_date) - julianday('2021-06-19') AS INTEGER)
+WHERE puzzle_number IS NULL;
+
+-- Share results with plain characters for terminals that can't render emoji
+ALTER TABLE user_settings ADD COLUMN ascii_share INTEGER DEFAULT 0;
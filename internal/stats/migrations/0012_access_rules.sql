@@ -0,0 +1,11 @@
+-- Block and allow rules for connections, replacing the compiled-in username blacklist
+CREATE TABLE IF NOT EXISTS access_rules (
+	id INTEGER PRIMARY KEY AUTOINCREMENT,
+	list TEXT NOT NULL,
+	kind TEXT NOT NULL,
+	value TEXT NOT NULL,
+	reason TEXT NOT NULL DEFAULT '',
+	expires_at DATETIME,
+	created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+	UNIQUE (list, kind, value)
+);
@@ -0,0 +1,224 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+)
+
+// baselineSchema is the schema created before migrations existed
+const baselineSchema = `
+	CREATE TABLE IF NOT EXISTS user_stats (
+		username TEXT NOT NULL,
+		ssh_key_fingerprint TEXT NOT NULL,
+		games_played INTEGER DEFAULT 0,
+		games_won INTEGER DEFAULT 0,
+		games_lost INTEGER DEFAULT 0,
+		current_streak INTEGER DEFAULT 0,
+		max_streak INTEGER DEFAULT 0,
+		guess_dist_1 INTEGER DEFAULT 0,
+		guess_dist_2 INTEGER DEFAULT 0,
+		guess_dist_3 INTEGER DEFAULT 0,
+		guess_dist_4 INTEGER DEFAULT 0,
+		guess_dist_5 INTEGER DEFAULT 0,
+		guess_dist_6 INTEGER DEFAULT 0,
+		total_guesses INTEGER DEFAULT 0,
+		last_played DATETIME,
+		last_word_date TEXT,
+		last_game_result TEXT,
+		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (username, ssh_key_fingerprint)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_last_played ON user_stats(last_played);
+	CREATE INDEX IF NOT EXISTS idx_games_won ON user_stats(games_won DESC);
+	CREATE INDEX IF NOT EXISTS idx_ssh_key ON user_stats(ssh_key_fingerprint);
+`
+
+// createBaselineDB creates a database with the baseline schema and the stats
+// of a user who played ten games, the last one a win in two guesses
+func createBaselineDB(t *testing.T, path string) {
+	t.Helper()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("failed to open database: %v", err)
+	}
+
+	defer func() {
+		_ = db.Close()
+	}()
+
+	if _, err := db.Exec(baselineSchema); err != nil {
+		t.Fatalf("failed to create baseline schema: %v", err)
+	}
+
+	insert := `
+		INSERT INTO user_stats (
+			username, ssh_key_fingerprint, games_played, games_won, games_lost, current_streak, max_streak,
+			guess_dist_1, guess_dist_2, guess_dist_3, guess_dist_4, guess_dist_5, guess_dist_6,
+			total_guesses, last_played, last_word_date, last_game_result
+		) VALUES ('alice', 'SHA256:alice', 10, 8, 2, 3, 5, 0, 2, 3, 2, 1, 0, 26, '2025-01-10 12:00:00', '2025-01-10', ?)
+	`
+
+	if _, err := db.Exec(insert, `{"w":true,"g":["SaLaAcTaEc","CcRcAcNcEc"]}`); err != nil {
+		t.Fatalf("failed to insert baseline stats: %v", err)
+	}
+}
+
+// checkMigratedStats checks that the user of createBaselineDB kept their stats
+// and has the last game imported into the history
+func checkMigratedStats(t *testing.T, store *Store) {
+	t.Helper()
+
+	userStats, err := store.GetUserStats("alice", "SHA256:alice")
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+
+	if userStats.GamesPlayed != 10 || userStats.GamesWon != 8 || userStats.GamesLost != 2 {
+		t.Errorf("games played/won/lost = %d/%d/%d, want 10/8/2", userStats.GamesPlayed, userStats.GamesWon, userStats.GamesLost)
+	}
+
+	if userStats.CurrentStreak != 3 || userStats.MaxStreak != 5 {
+		t.Errorf("current/max streak = %d/%d, want 3/5", userStats.CurrentStreak, userStats.MaxStreak)
+	}
+
+	if want := [6]int{0, 2, 3, 2, 1, 0}; userStats.GuessDistribution != want {
+		t.Errorf("guess distribution = %v, want %v", userStats.GuessDistribution, want)
+	}
+
+	if userStats.TotalGuesses != 26 || userStats.LastWordDate != "2025-01-10" {
+		t.Errorf("total guesses = %d last word date = %q, want 26 2025-01-10", userStats.TotalGuesses, userStats.LastWordDate)
+	}
+
+	games, err := store.GetGames("alice", "SHA256:alice")
+	if err != nil {
+		t.Fatalf("GetGames() error = %v", err)
+	}
+
+	if len(games) != 1 || games[0].WordDate != "2025-01-10" || !games[0].Won || games[0].Guesses != 2 || !games[0].Imported {
+		t.Errorf("games = %+v, want the imported win of 2025-01-10", games)
+	}
+}
+
+func TestMigrateBaselineDatabase(t *testing.T) {
+	path := testDBPath(t)
+	createBaselineDB(t, path)
+
+	store, err := NewStore(path, testLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	defer func() {
+		_ = store.Close()
+	}()
+
+	migrations, err := store.migrations()
+	if err != nil {
+		t.Fatalf("migrations() error = %v", err)
+	}
+
+	version, err := store.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion() error = %v", err)
+	}
+
+	if version != len(migrations) {
+		t.Errorf("SchemaVersion() = %d, want %d", version, len(migrations))
+	}
+
+	checkMigratedStats(t, store)
+
+	rules, err := store.ListAccessRules()
+	if err != nil {
+		t.Fatalf("ListAccessRules() error = %v", err)
+	}
+
+	if len(rules) != len(defaultBlockedUsernames) {
+		t.Errorf("ListAccessRules() returned %d rules, want the %d default blocks", len(rules), len(defaultBlockedUsernames))
+	}
+}
+
+func TestMigrateIsIdempotent(t *testing.T) {
+	path := testDBPath(t)
+	createBaselineDB(t, path)
+
+	first, err := NewStore(path, testLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	version, err := first.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion() error = %v", err)
+	}
+
+	_ = first.Close()
+
+	store, err := NewStore(path, testLogger())
+	if err != nil {
+		t.Fatalf("NewStore() on a migrated database error = %v", err)
+	}
+
+	defer func() {
+		_ = store.Close()
+	}()
+
+	if again, err := store.SchemaVersion(); err != nil || again != version {
+		t.Errorf("SchemaVersion() after reopening = %d, %v, want %d", again, err, version)
+	}
+
+	var applied int
+	if err := store.db.QueryRow(`SELECT COUNT(*) FROM schema_version`).Scan(&applied); err != nil {
+		t.Fatalf("failed to count applied migrations: %v", err)
+	}
+
+	if applied != version {
+		t.Errorf("schema_version has %d rows, want %d", applied, version)
+	}
+
+	// The data migrations must not seed anything twice
+	checkMigratedStats(t, store)
+
+	rules, err := store.ListAccessRules()
+	if err != nil {
+		t.Fatalf("ListAccessRules() error = %v", err)
+	}
+
+	if len(rules) != len(defaultBlockedUsernames) {
+		t.Errorf("ListAccessRules() returned %d rules after reopening, want %d", len(rules), len(defaultBlockedUsernames))
+	}
+}
+
+func TestMigrateRefusesNewerSchema(t *testing.T) {
+	path := testDBPath(t)
+
+	store, err := NewStore(path, testLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	version, err := store.SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion() error = %v", err)
+	}
+
+	if _, err := store.db.Exec(`INSERT INTO schema_version (version, name) VALUES (?, 'from_the_future')`, version+1); err != nil {
+		t.Fatalf("failed to record a newer migration: %v", err)
+	}
+
+	_ = store.Close()
+
+	store, err = NewStore(path, testLogger())
+	if err == nil {
+		_ = store.Close()
+		t.Fatal("NewStore() on a newer schema succeeded, want an error")
+	}
+
+	if !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("NewStore() error = %v, want %v", err, ErrSchemaTooNew)
+	}
+}
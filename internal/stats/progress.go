@@ -0,0 +1,270 @@
+package stats
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+)
+
+// GameProgress is a game that has been started but not finished
+type GameProgress struct {
+	Username          string
+	SSHKeyFingerprint string
+	WordDate          string
+	Mode              GameMode
+	Game              *engine.Game
+	StartedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// SaveGameProgress saves the current state of an unfinished game. It returns
+// ErrAlreadyRecorded once the game of the puzzle date has been recorded, e.g.
+// by another session of the same user, so the progress is not resurrected.
+func (s *Store) SaveGameProgress(progress *GameProgress) error {
+	defer metrics.ObserveQuery("save_game_progress")()
+
+	state, err := json.Marshal(progress.Game)
+	if err != nil {
+		return fmt.Errorf("failed to encode game state: %w", err)
+	}
+
+	progress.UpdatedAt = time.Now()
+	if progress.StartedAt.IsZero() {
+		progress.StartedAt = progress.UpdatedAt
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	query := `
+		INSERT INTO game_progress (username, ssh_key_fingerprint, word_date, mode, game_state, started_at, updated_at)
+		SELECT ?, ?, ?, ?, ?, ?, ?
+		WHERE NOT EXISTS (
+			SELECT 1 FROM games WHERE username = ? AND ssh_key_fingerprint = ? AND word_date = ?
+		)
+		ON CONFLICT(username, ssh_key_fingerprint, word_date) DO UPDATE SET
+			game_state = excluded.game_state,
+			updated_at = excluded.updated_at
+	`
+
+	result, err := tx.Exec(query,
+		progress.Username,
+		progress.SSHKeyFingerprint,
+		progress.WordDate,
+		string(progress.Mode),
+		string(state),
+		progress.StartedAt,
+		progress.UpdatedAt,
+		progress.Username,
+		progress.SSHKeyFingerprint,
+		progress.WordDate,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save game progress: %w", err)
+	}
+
+	saved, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to save game progress: %w", err)
+	}
+
+	if saved == 0 {
+		return ErrAlreadyRecorded
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit game progress: %w", err)
+	}
+
+	s.logger.Debug("Saved game progress", "username", progress.Username, "word_date", progress.WordDate, "guesses", progress.Game.GuessCount())
+	return nil
+}
+
+// GetGameProgress retrieves the unfinished game of a user for a puzzle date, returns nil if there is none
+func (s *Store) GetGameProgress(username string, sshKeyFingerprint string, wordDate string) (*GameProgress, error) {
+	defer metrics.ObserveQuery("get_game_progress")()
+
+	query := `
+		SELECT username, ssh_key_fingerprint, word_date, mode, game_state, started_at, updated_at
+		FROM game_progress
+		WHERE username = ? AND ssh_key_fingerprint = ? AND word_date = ?
+	`
+
+	progress, err := scanGameProgress(s.db.QueryRow(query, username, sshKeyFingerprint, wordDate))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get game progress: %w", err)
+	}
+
+	return progress, nil
+}
+
+// deleteGameProgress removes the unfinished game of a user for a puzzle date
+func (s *Store) deleteGameProgress(q querier, username string, sshKeyFingerprint string, wordDate string) error {
+	query := `DELETE FROM game_progress WHERE username = ? AND ssh_key_fingerprint = ? AND word_date = ?`
+	if _, err := q.Exec(query, username, sshKeyFingerprint, wordDate); err != nil {
+		return fmt.Errorf("failed to delete game progress: %w", err)
+	}
+
+	return nil
+}
+
+// FinalizeAbandonedGames records unfinished daily games of puzzle dates before
+// the given date as losses and returns how many were finalized. Games that
+// can't be decoded or recorded, e.g. saved under an older wordlist, are logged
+// and skipped so they don't hold back the others.
+func (s *Store) FinalizeAbandonedGames(before string) (int, error) {
+	defer metrics.ObserveQuery("finalize_abandoned_games")()
+
+	query := `
+		SELECT username, ssh_key_fingerprint, word_date, mode, game_state, started_at, updated_at
+		FROM game_progress
+		WHERE mode = ? AND word_date < ?
+	`
+
+	rows, err := s.db.Query(query, string(GameModeDaily), before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find abandoned games: %w", err)
+	}
+
+	type abandonedGame struct {
+		progress *GameProgress
+		state    string
+	}
+
+	var abandoned []abandonedGame
+	for rows.Next() {
+		progress, state, err := scanGameProgressRow(rows)
+		if err != nil {
+			s.logger.Warn("Skipping unreadable abandoned game", "error", err)
+			continue
+		}
+
+		abandoned = append(abandoned, abandonedGame{progress: progress, state: state})
+	}
+
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("failed to find abandoned games: %w", err)
+	}
+
+	finalized := 0
+	for _, game := range abandoned {
+		progress := game.progress
+		if err := decodeGameState(progress, game.state); err != nil {
+			s.logger.Warn("Skipping abandoned game with invalid state",
+				"error", err,
+				"username", progress.Username,
+				"word_date", progress.WordDate,
+			)
+			continue
+		}
+
+		if err := s.finalizeAbandonedGame(progress); err != nil {
+			s.logger.Error("Failed to finalize abandoned game",
+				"error", err,
+				"username", progress.Username,
+				"word_date", progress.WordDate,
+			)
+			continue
+		}
+
+		finalized++
+		s.logger.Info("Finalized abandoned game as loss",
+			"username", progress.Username,
+			"word_date", progress.WordDate,
+			"guesses", progress.Game.GuessCount(),
+		)
+	}
+
+	return finalized, nil
+}
+
+// finalizeAbandonedGame records a single abandoned game as a loss
+func (s *Store) finalizeAbandonedGame(progress *GameProgress) error {
+	result, err := progress.Game.Result().JSON()
+	if err != nil {
+		return fmt.Errorf("failed to encode game result: %w", err)
+	}
+
+	game := &Game{
+		Username:          progress.Username,
+		SSHKeyFingerprint: progress.SSHKeyFingerprint,
+		WordDate:          progress.WordDate,
+		Mode:              progress.Mode,
+		HardMode:          progress.Game.HardMode(),
+		Guesses:           progress.Game.GuessCount(),
+		GameResult:        result,
+		StartedAt:         progress.StartedAt,
+	}
+
+	_, err = s.recordGame(game)
+	if errors.Is(err, ErrAlreadyRecorded) {
+		// A result was recorded after all, the progress is just stale
+		return s.deleteGameProgress(s.db, progress.Username, progress.SSHKeyFingerprint, progress.WordDate)
+	}
+
+	return err
+}
+
+// scanGameProgress scans a game_progress row and decodes its game
+func scanGameProgress(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*GameProgress, error) {
+	progress, state, err := scanGameProgressRow(scanner)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeGameState(progress, state); err != nil {
+		return nil, err
+	}
+
+	return progress, nil
+}
+
+// scanGameProgressRow scans a game_progress row, returning the encoded game separately
+func scanGameProgressRow(scanner interface {
+	Scan(dest ...interface{}) error
+}) (*GameProgress, string, error) {
+	var progress GameProgress
+	var mode, state string
+
+	if err := scanner.Scan(
+		&progress.Username,
+		&progress.SSHKeyFingerprint,
+		&progress.WordDate,
+		&mode,
+		&state,
+		&progress.StartedAt,
+		&progress.UpdatedAt,
+	); err != nil {
+		return nil, "", err
+	}
+
+	progress.Mode = GameMode(mode)
+	return &progress, state, nil
+}
+
+// decodeGameState restores the game of a progress by replaying its guesses
+func decodeGameState(progress *GameProgress, state string) error {
+	if err := json.Unmarshal([]byte(state), &progress.Game); err != nil {
+		return fmt.Errorf("failed to decode game state: %w", err)
+	}
+
+	return nil
+}
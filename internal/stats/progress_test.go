@@ -0,0 +1,106 @@
+package stats
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
+)
+
+// saveTestProgress saves an unfinished daily game of alice with one guess
+func saveTestProgress(t *testing.T, store *Store, wordDate string) {
+	t.Helper()
+
+	game, err := engine.Restore("crane", false, []string{"slate"})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	progress := &GameProgress{
+		Username:          "alice",
+		SSHKeyFingerprint: "SHA256:alice",
+		WordDate:          wordDate,
+		Mode:              GameModeDaily,
+		Game:              game,
+	}
+
+	if err := store.SaveGameProgress(progress); err != nil {
+		t.Fatalf("SaveGameProgress() error = %v", err)
+	}
+}
+
+func TestFinalizeAbandonedGamesSkipsInvalidState(t *testing.T) {
+	store := newTestStore(t)
+
+	for _, date := range []string{"2025-01-08", "2025-01-09", "2025-01-10"} {
+		saveTestProgress(t, store, date)
+	}
+
+	// A game saved under an older wordlist and one that is not JSON at all
+	update := `UPDATE game_progress SET game_state = ? WHERE word_date = ?`
+	if _, err := store.db.Exec(update, `{"solution":"crane","guesses":["zzzzz"]}`, "2025-01-08"); err != nil {
+		t.Fatalf("failed to corrupt game state: %v", err)
+	}
+
+	if _, err := store.db.Exec(update, `not json`, "2025-01-09"); err != nil {
+		t.Fatalf("failed to corrupt game state: %v", err)
+	}
+
+	count, err := store.FinalizeAbandonedGames("2025-01-11")
+	if err != nil {
+		t.Fatalf("FinalizeAbandonedGames() error = %v", err)
+	}
+
+	if count != 1 {
+		t.Errorf("FinalizeAbandonedGames() = %d, want 1", count)
+	}
+
+	games, err := store.GetGames("alice", "SHA256:alice")
+	if err != nil {
+		t.Fatalf("GetGames() error = %v", err)
+	}
+
+	if len(games) != 1 || games[0].WordDate != "2025-01-10" || games[0].Won {
+		t.Errorf("games = %+v, want the loss of 2025-01-10", games)
+	}
+}
+
+func TestSaveGameProgressAfterRecord(t *testing.T) {
+	store := newTestStore(t)
+
+	saveTestProgress(t, store, "2025-01-10")
+
+	// Another session of alice finishes the puzzle, which removes the progress
+	if err := store.RecordWin(testGame("2025-01-10")); err != nil {
+		t.Fatalf("RecordWin() error = %v", err)
+	}
+
+	game, err := engine.Restore("crane", false, []string{"slate", "trace"})
+	if err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	err = store.SaveGameProgress(&GameProgress{
+		Username:          "alice",
+		SSHKeyFingerprint: "SHA256:alice",
+		WordDate:          "2025-01-10",
+		Mode:              GameModeDaily,
+		Game:              game,
+	})
+	if !errors.Is(err, ErrAlreadyRecorded) {
+		t.Errorf("SaveGameProgress() of a recorded puzzle error = %v, want %v", err, ErrAlreadyRecorded)
+	}
+
+	progress, err := store.GetGameProgress("alice", "SHA256:alice", "2025-01-10")
+	if err != nil {
+		t.Fatalf("GetGameProgress() error = %v", err)
+	}
+
+	if progress != nil {
+		t.Errorf("GetGameProgress() = %d guesses, want no progress for a recorded puzzle", progress.Game.GuessCount())
+	}
+
+	// Updating the progress of an unrecorded puzzle still works
+	saveTestProgress(t, store, "2025-01-11")
+	saveTestProgress(t, store, "2025-01-11")
+}
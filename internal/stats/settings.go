@@ -0,0 +1,115 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
+)
+
+// UserSettings holds per-user preferences
+type UserSettings struct {
+	Username          string
+	SSHKeyFingerprint string
+	Timezone          string // IANA timezone name, empty for the server's timezone
+	HardMode          bool   // Revealed hints must be used in subsequent guesses
+	ShowOnLeaderboard *bool  // Nil follows the server default
+	ASCIIShare        bool   // Share results without emoji
+}
+
+// Location returns the user's timezone, falling back to the server's timezone
+func (settings *UserSettings) Location() *time.Location {
+	if settings.Timezone == "" {
+		return time.Local
+	}
+
+	loc, err := time.LoadLocation(settings.Timezone)
+	if err != nil {
+		return time.Local
+	}
+
+	return loc
+}
+
+// OnLeaderboard returns whether the user appears on leaderboards
+func (settings *UserSettings) OnLeaderboard(optIn bool) bool {
+	if settings.ShowOnLeaderboard == nil {
+		return !optIn
+	}
+
+	return *settings.ShowOnLeaderboard
+}
+
+// Today returns the current puzzle date in the user's timezone
+func (settings *UserSettings) Today() string {
+	return time.Now().In(settings.Location()).Format(DateFormat)
+}
+
+// GetUserSettings retrieves the settings for a user, returns defaults if none are stored
+func (s *Store) GetUserSettings(username string, sshKeyFingerprint string) (*UserSettings, error) {
+	defer metrics.ObserveQuery("get_user_settings")()
+
+	query := `
+		SELECT COALESCE(timezone, ''), COALESCE(hard_mode, 0), show_on_leaderboard, COALESCE(ascii_share, 0)
+		FROM user_settings
+		WHERE username = ? AND ssh_key_fingerprint = ?
+	`
+
+	settings := UserSettings{
+		Username:          username,
+		SSHKeyFingerprint: sshKeyFingerprint,
+	}
+
+	var showOnLeaderboard sql.NullBool
+	err := s.db.QueryRow(query, username, sshKeyFingerprint).Scan(&settings.Timezone, &settings.HardMode, &showOnLeaderboard, &settings.ASCIIShare)
+	if errors.Is(err, sql.ErrNoRows) {
+		return &settings, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user settings: %w", err)
+	}
+
+	if showOnLeaderboard.Valid {
+		settings.ShowOnLeaderboard = &showOnLeaderboard.Bool
+	}
+
+	return &settings, nil
+}
+
+// SaveUserSettings saves or updates the settings for a user
+func (s *Store) SaveUserSettings(settings *UserSettings) error {
+	defer metrics.ObserveQuery("save_user_settings")()
+
+	if settings.Timezone != "" {
+		if _, err := time.LoadLocation(settings.Timezone); err != nil {
+			return fmt.Errorf("invalid timezone %q: %w", settings.Timezone, err)
+		}
+	}
+
+	s.logger.Debug("Saving user settings", "username", settings.Username, "timezone", settings.Timezone, "hard_mode", settings.HardMode)
+
+	query := `
+		INSERT INTO user_settings (username, ssh_key_fingerprint, timezone, hard_mode, show_on_leaderboard, ascii_share, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+		ON CONFLICT(username, ssh_key_fingerprint) DO UPDATE SET
+			timezone = excluded.timezone,
+			hard_mode = excluded.hard_mode,
+			show_on_leaderboard = excluded.show_on_leaderboard,
+			ascii_share = excluded.ascii_share,
+			updated_at = CURRENT_TIMESTAMP
+	`
+
+	var showOnLeaderboard sql.NullBool
+	if settings.ShowOnLeaderboard != nil {
+		showOnLeaderboard = sql.NullBool{Bool: *settings.ShowOnLeaderboard, Valid: true}
+	}
+
+	if _, err := s.db.Exec(query, settings.Username, settings.SSHKeyFingerprint, settings.Timezone, settings.HardMode, showOnLeaderboard, settings.ASCIIShare); err != nil {
+		return fmt.Errorf("failed to save user settings: %w", err)
+	}
+
+	return nil
+}
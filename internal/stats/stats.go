@@ -1,15 +1,21 @@
 package stats
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/log"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
 	_ "github.com/mattn/go-sqlite3"
 )
 
+// DateFormat is the layout of puzzle dates
+const DateFormat = "2006-01-02"
+
 // UserStats represents a user's game statistics
 type UserStats struct {
 	Username          string
@@ -24,70 +30,58 @@ type UserStats struct {
 	LastPlayed        time.Time
 	LastWordDate      string // To prevent playing same word twice
 	LastGameResult    string // JSON-encoded game result for display
+
+	// Hard mode games are also counted separately
+	HardModeGamesPlayed int
+	HardModeGamesWon    int
+	HardModeGuesses     int
+}
+
+// querier is implemented by both *sql.DB and *sql.Tx
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 // Store handles database operations for user statistics
 type Store struct {
-	db     *sql.DB
-	logger *log.Logger
+	db          *sql.DB
+	logger      *log.Logger
+	leaderboard LeaderboardConfig
 }
 
 // NewStore creates a new statistics store
 func NewStore(dbPath string, logger *log.Logger) (*Store, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	// Wait for concurrent writers instead of failing with SQLITE_BUSY, and take
+	// the write lock when a transaction begins so read-then-write can't race
+	db, err := sql.Open("sqlite3", withParams(dbPath, "_busy_timeout=5000", "_txlock=immediate"))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
 
 	store := &Store{
-		db:     db,
-		logger: logger,
+		db:          db,
+		logger:      logger,
+		leaderboard: LeaderboardConfig{MinGames: defaultLeaderboardMinGames},
 	}
 
-	if err := store.initSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	if err := store.migrate(); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to migrate database: %w", err)
 	}
 
 	return store, nil
 }
 
-// initSchema creates the necessary database tables
-func (s *Store) initSchema() error {
-	schema := `
-	CREATE TABLE IF NOT EXISTS user_stats (
-		username TEXT NOT NULL,
-		ssh_key_fingerprint TEXT NOT NULL,
-		games_played INTEGER DEFAULT 0,
-		games_won INTEGER DEFAULT 0,
-		games_lost INTEGER DEFAULT 0,
-		current_streak INTEGER DEFAULT 0,
-		max_streak INTEGER DEFAULT 0,
-		guess_dist_1 INTEGER DEFAULT 0,
-		guess_dist_2 INTEGER DEFAULT 0,
-		guess_dist_3 INTEGER DEFAULT 0,
-		guess_dist_4 INTEGER DEFAULT 0,
-		guess_dist_5 INTEGER DEFAULT 0,
-		guess_dist_6 INTEGER DEFAULT 0,
-		total_guesses INTEGER DEFAULT 0,
-		last_played DATETIME,
-		last_word_date TEXT,
-		last_game_result TEXT,
-		created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
-		PRIMARY KEY (username, ssh_key_fingerprint)
-	);
-
-	CREATE INDEX IF NOT EXISTS idx_last_played ON user_stats(last_played);
-	CREATE INDEX IF NOT EXISTS idx_games_won ON user_stats(games_won DESC);
-	CREATE INDEX IF NOT EXISTS idx_ssh_key ON user_stats(ssh_key_fingerprint);
-	`
-
-	if _, err := s.db.Exec(schema); err != nil {
-		return fmt.Errorf("failed to create schema: %w", err)
+// withParams appends connection parameters to a database path
+func withParams(dbPath string, params ...string) string {
+	separator := "?"
+	if strings.Contains(dbPath, "?") {
+		separator = "&"
 	}
 
-	s.logger.Debug("Database schema initialized")
-	return nil
+	return dbPath + separator + strings.Join(params, "&")
 }
 
 // scanUserStats is a helper method to scan user stats from a row scanner
@@ -129,6 +123,13 @@ func (s *Store) scanUserStats(scanner interface {
 
 // GetUserStats retrieves statistics for a user by username AND ssh_key_fingerprint pair
 func (s *Store) GetUserStats(username string, sshKeyFingerprint string) (*UserStats, error) {
+	defer metrics.ObserveQuery("get_user_stats")()
+
+	return s.getUserStats(s.db, username, sshKeyFingerprint)
+}
+
+// getUserStats retrieves statistics for a user using the given querier
+func (s *Store) getUserStats(q querier, username string, sshKeyFingerprint string) (*UserStats, error) {
 	s.logger.Debug("Reading user stats", "username", username, "ssh_key_fingerprint", sshKeyFingerprint)
 
 	query := `
@@ -141,7 +142,7 @@ func (s *Store) GetUserStats(username string, sshKeyFingerprint string) (*UserSt
 
 	var stats UserStats
 
-	err := s.scanUserStats(s.db.QueryRow(query, username, sshKeyFingerprint), &stats)
+	err := s.scanUserStats(q.QueryRow(query, username, sshKeyFingerprint), &stats)
 	if errors.Is(err, sql.ErrNoRows) {
 		// Return empty stats for new user
 		s.logger.Debug("No existing stats found, returning empty stats for new user", "username", username)
@@ -155,6 +156,20 @@ func (s *Store) GetUserStats(username string, sshKeyFingerprint string) (*UserSt
 		return nil, fmt.Errorf("failed to get user stats: %w", err)
 	}
 
+	hardModeQuery := `
+		SELECT COUNT(*), COALESCE(SUM(won), 0), COALESCE(SUM(CASE WHEN won THEN guesses ELSE 0 END), 0)
+		FROM games
+		WHERE username = ? AND ssh_key_fingerprint = ? AND hard_mode = 1
+	`
+
+	if err := q.QueryRow(hardModeQuery, username, sshKeyFingerprint).Scan(
+		&stats.HardModeGamesPlayed,
+		&stats.HardModeGamesWon,
+		&stats.HardModeGuesses,
+	); err != nil {
+		return nil, fmt.Errorf("failed to get hard mode stats: %w", err)
+	}
+
 	s.logger.Debug("Successfully retrieved user stats",
 		"username", username,
 		"games_played", stats.GamesPlayed,
@@ -175,7 +190,8 @@ func (s *Store) HasPlayedToday(username string, sshKeyFingerprint string, wordDa
 		return false, err
 	}
 
-	hasPlayed := stats.LastWordDate == wordDate
+	// Dates compare lexically, a later last date means the user moved to an earlier timezone
+	hasPlayed := stats.LastWordDate != "" && stats.LastWordDate >= wordDate
 	s.logger.Debug("Played today check result",
 		"username", username,
 		"has_played", hasPlayed,
@@ -186,62 +202,88 @@ func (s *Store) HasPlayedToday(username string, sshKeyFingerprint string, wordDa
 	return hasPlayed, nil
 }
 
-// RecordWin records a winning game for a user
-func (s *Store) RecordWin(username string, sshKeyFingerprint string, guesses int, wordDate string, gameResult string) error {
-	if guesses < 1 || guesses > 6 {
-		return fmt.Errorf("invalid number of guesses: %d", guesses)
+// RecordWin records a winning game for a user.
+// Archive games count towards the totals but leave the daily streak untouched.
+func (s *Store) RecordWin(game *Game) error {
+	if game.Guesses < 1 || game.Guesses > 6 {
+		return fmt.Errorf("invalid number of guesses: %d", game.Guesses)
 	}
 
-	stats, err := s.GetUserStats(username, sshKeyFingerprint)
+	game.Won = true
+	stats, err := s.recordGame(game)
 	if err != nil {
 		return err
 	}
 
-	stats.GamesPlayed++
-	stats.GamesWon++
-	stats.CurrentStreak++
-	stats.TotalGuesses += guesses
-	stats.GuessDistribution[guesses-1]++
-	stats.LastPlayed = time.Now()
-	stats.LastWordDate = wordDate
-	stats.LastGameResult = gameResult
-
-	if stats.CurrentStreak > stats.MaxStreak {
-		stats.MaxStreak = stats.CurrentStreak
-	}
+	s.logger.Info("Recorded win",
+		"username", game.Username,
+		"mode", game.Mode,
+		"hard_mode", game.HardMode,
+		"guesses", game.Guesses,
+		"streak", stats.CurrentStreak,
+	)
+	return nil
+}
 
-	if err := s.saveUserStats(stats); err != nil {
+// RecordLoss records a losing game for a user.
+// Archive games count towards the totals but leave the daily streak untouched.
+func (s *Store) RecordLoss(game *Game) error {
+	game.Won = false
+	if _, err := s.recordGame(game); err != nil {
 		return err
 	}
 
-	s.logger.Info("Recorded win", "username", username, "guesses", guesses, "streak", stats.CurrentStreak)
+	s.logger.Info("Recorded loss", "username", game.Username, "mode", game.Mode, "hard_mode", game.HardMode)
 	return nil
 }
 
-// RecordLoss records a losing game for a user
-func (s *Store) RecordLoss(username string, sshKeyFingerprint string, wordDate string, gameResult string) error {
-	stats, err := s.GetUserStats(username, sshKeyFingerprint)
+// recordGame adds a finished game to the history and updates the user's aggregates
+// in a single transaction
+func (s *Store) recordGame(game *Game) (*UserStats, error) {
+	defer metrics.ObserveQuery("record_game")()
+
+	game.FinishedAt = time.Now()
+	if game.StartedAt.IsZero() {
+		game.StartedAt = game.FinishedAt
+	}
+
+	tx, err := s.db.Begin()
 	if err != nil {
-		return err
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
-	stats.GamesPlayed++
-	stats.GamesLost++
-	stats.CurrentStreak = 0 // Reset streak on loss
-	stats.LastPlayed = time.Now()
-	stats.LastWordDate = wordDate
-	stats.LastGameResult = gameResult
+	defer func() {
+		_ = tx.Rollback()
+	}()
 
-	if err := s.saveUserStats(stats); err != nil {
-		return err
+	if err := s.insertGame(tx, game); err != nil {
+		return nil, err
 	}
 
-	s.logger.Info("Recorded loss", "username", username)
-	return nil
+	if err := s.deleteGameProgress(tx, game.Username, game.SSHKeyFingerprint, game.WordDate); err != nil {
+		return nil, err
+	}
+
+	stats, err := s.recomputeUserStats(tx, game.Username, game.SSHKeyFingerprint)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit game: %w", err)
+	}
+
+	result := "lost"
+	if game.Won {
+		result = "won"
+	}
+	metrics.GamesFinished.WithLabelValues(string(game.Mode), result).Inc()
+
+	return stats, nil
 }
 
 // saveUserStats saves or updates user statistics
-func (s *Store) saveUserStats(stats *UserStats) error {
+func (s *Store) saveUserStats(q querier, stats *UserStats) error {
 	// Create a hash of the SSH public key for logging
 
 	s.logger.Debug("Saving user stats",
@@ -281,7 +323,7 @@ func (s *Store) saveUserStats(stats *UserStats) error {
 			updated_at = CURRENT_TIMESTAMP
 	`
 
-	_, err := s.db.Exec(query,
+	_, err := q.Exec(query,
 		stats.Username,
 		stats.SSHKeyFingerprint,
 		stats.GamesPlayed,
@@ -309,6 +351,26 @@ func (s *Store) saveUserStats(stats *UserStats) error {
 	return nil
 }
 
+// CurrentStreakOn returns the streak as of the given puzzle date, which is zero
+// once a day has been missed
+func (stats *UserStats) CurrentStreakOn(today string) int {
+	if stats.LastWordDate == today || stats.LastWordDate == previousDate(today) {
+		return stats.CurrentStreak
+	}
+
+	return 0
+}
+
+// previousDate returns the puzzle date before the given one
+func previousDate(date string) string {
+	t, err := time.Parse(DateFormat, date)
+	if err != nil {
+		return ""
+	}
+
+	return t.AddDate(0, 0, -1).Format(DateFormat)
+}
+
 // GetAverageGuesses calculates the average number of guesses for a user
 func (stats *UserStats) GetAverageGuesses() float64 {
 	if stats.GamesWon == 0 {
@@ -317,6 +379,22 @@ func (stats *UserStats) GetAverageGuesses() float64 {
 	return float64(stats.TotalGuesses) / float64(stats.GamesWon)
 }
 
+// GetHardModeWinRate calculates the hard mode win rate percentage
+func (stats *UserStats) GetHardModeWinRate() float64 {
+	if stats.HardModeGamesPlayed == 0 {
+		return 0
+	}
+	return float64(stats.HardModeGamesWon) / float64(stats.HardModeGamesPlayed) * 100
+}
+
+// GetHardModeAverageGuesses calculates the average number of guesses for hard mode wins
+func (stats *UserStats) GetHardModeAverageGuesses() float64 {
+	if stats.HardModeGamesWon == 0 {
+		return 0
+	}
+	return float64(stats.HardModeGuesses) / float64(stats.HardModeGamesWon)
+}
+
 // GetWinRate calculates the win rate percentage
 func (stats *UserStats) GetWinRate() float64 {
 	if stats.GamesPlayed == 0 {
@@ -327,24 +405,69 @@ func (stats *UserStats) GetWinRate() float64 {
 
 // DeleteUserData deletes all data for a specific user
 func (s *Store) DeleteUserData(username string, sshKeyFingerprint string) error {
+	defer metrics.ObserveQuery("delete_user_data")()
+
 	s.logger.Info("Deleting user data", "username", username, "ssh_key_fingerprint", sshKeyFingerprint)
 
+	// Delete everything at once, so a game recorded meanwhile can't leave stats behind
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
 	query := `DELETE FROM user_stats WHERE username = ? AND ssh_key_fingerprint = ?`
 
-	result, err := s.db.Exec(query, username, sshKeyFingerprint)
+	result, err := tx.Exec(query, username, sshKeyFingerprint)
 	if err != nil {
 		return fmt.Errorf("failed to delete user data: %w", err)
 	}
 
+	settingsQuery := `DELETE FROM user_settings WHERE username = ? AND ssh_key_fingerprint = ?`
+	if _, err := tx.Exec(settingsQuery, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to delete user settings: %w", err)
+	}
+
+	gamesQuery := `DELETE FROM games WHERE username = ? AND ssh_key_fingerprint = ?`
+	if _, err := tx.Exec(gamesQuery, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to delete game history: %w", err)
+	}
+
+	progressQuery := `DELETE FROM game_progress WHERE username = ? AND ssh_key_fingerprint = ?`
+	if _, err := tx.Exec(progressQuery, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to delete game progress: %w", err)
+	}
+
+	if err := deleteUserGroups(tx, username, sshKeyFingerprint); err != nil {
+		return err
+	}
+
+	baselineQuery := `DELETE FROM stats_baseline WHERE username = ? AND ssh_key_fingerprint = ?`
+	if _, err := tx.Exec(baselineQuery, username, sshKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to delete stats baseline: %w", err)
+	}
+
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit user data deletion: %w", err)
+	}
+
 	s.logger.Info("User data deleted", "username", username, "rows_affected", rowsAffected)
 	return nil
 }
 
+// Ping checks that the database can be reached
+func (s *Store) Ping(ctx context.Context) error {
+	return s.db.PingContext(ctx)
+}
+
 // Close closes the database connection
 func (s *Store) Close() error {
 	if s.db != nil {
@@ -0,0 +1,204 @@
+package stats
+
+import (
+	"errors"
+	"sync"
+	"testing"
+)
+
+// testGame returns a daily game of alice, won in three guesses
+func testGame(wordDate string) *Game {
+	return &Game{
+		Username:          "alice",
+		SSHKeyFingerprint: "SHA256:alice",
+		WordDate:          wordDate,
+		Mode:              GameModeDaily,
+		Guesses:           3,
+		GameResult:        `{"w":true,"g":[]}`,
+	}
+}
+
+// countGames returns the number of recorded games of alice for a date
+func countGames(t *testing.T, store *Store, wordDate string) int {
+	t.Helper()
+
+	var count int
+	query := `SELECT COUNT(*) FROM games WHERE username = 'alice' AND ssh_key_fingerprint = 'SHA256:alice' AND word_date = ?`
+	if err := store.db.QueryRow(query, wordDate).Scan(&count); err != nil {
+		t.Fatalf("failed to count games: %v", err)
+	}
+
+	return count
+}
+
+func TestRecordGameTwice(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.RecordWin(testGame("2025-01-10")); err != nil {
+		t.Fatalf("RecordWin() error = %v", err)
+	}
+
+	// A second session of the same user finishing the same puzzle
+	if err := store.RecordLoss(testGame("2025-01-10")); !errors.Is(err, ErrAlreadyRecorded) {
+		t.Errorf("RecordLoss() of a recorded puzzle error = %v, want %v", err, ErrAlreadyRecorded)
+	}
+
+	if count := countGames(t, store, "2025-01-10"); count != 1 {
+		t.Errorf("recorded %d games, want 1", count)
+	}
+
+	userStats, err := store.GetUserStats("alice", "SHA256:alice")
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+
+	if userStats.GamesPlayed != 1 || userStats.GamesWon != 1 || userStats.GamesLost != 0 {
+		t.Errorf("games played/won/lost = %d/%d/%d, want 1/1/0", userStats.GamesPlayed, userStats.GamesWon, userStats.GamesLost)
+	}
+
+	// Another date is a separate game
+	if err := store.RecordWin(testGame("2025-01-11")); err != nil {
+		t.Errorf("RecordWin() of the next puzzle error = %v", err)
+	}
+}
+
+func TestRecordGameConcurrently(t *testing.T) {
+	store := newTestStore(t)
+
+	const sessions = 8
+
+	var wg sync.WaitGroup
+	errs := make(chan error, sessions)
+	for range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- store.RecordWin(testGame("2025-01-10"))
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	recorded := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			recorded++
+		case !errors.Is(err, ErrAlreadyRecorded):
+			t.Errorf("RecordWin() error = %v, want nil or %v", err, ErrAlreadyRecorded)
+		}
+	}
+
+	if recorded != 1 {
+		t.Errorf("%d sessions recorded the game, want 1", recorded)
+	}
+
+	if count := countGames(t, store, "2025-01-10"); count != 1 {
+		t.Errorf("recorded %d games, want 1", count)
+	}
+
+	userStats, err := store.GetUserStats("alice", "SHA256:alice")
+	if err != nil {
+		t.Fatalf("GetUserStats() error = %v", err)
+	}
+
+	if userStats.GamesPlayed != 1 {
+		t.Errorf("games played = %d, want 1", userStats.GamesPlayed)
+	}
+}
+
+// countRows returns the number of rows of alice in a table
+func countRows(t *testing.T, store *Store, table string) int {
+	t.Helper()
+
+	var count int
+	query := `SELECT COUNT(*) FROM ` + table + ` WHERE username = 'alice' AND ssh_key_fingerprint = 'SHA256:alice'`
+	if err := store.db.QueryRow(query).Scan(&count); err != nil {
+		t.Fatalf("failed to count rows of %s: %v", table, err)
+	}
+
+	return count
+}
+
+// seedUserData gives alice stats, settings, a baseline, an unfinished game
+// and a group
+func seedUserData(t *testing.T, store *Store) {
+	t.Helper()
+
+	if err := store.RecordWin(testGame("2025-01-10")); err != nil {
+		t.Fatalf("RecordWin() error = %v", err)
+	}
+
+	saveTestProgress(t, store, "2025-01-11")
+
+	if err := store.SaveUserSettings(&UserSettings{Username: "alice", SSHKeyFingerprint: "SHA256:alice", Timezone: "UTC"}); err != nil {
+		t.Fatalf("SaveUserSettings() error = %v", err)
+	}
+
+	if _, err := store.db.Exec(`INSERT INTO stats_baseline (username, ssh_key_fingerprint, games_played) VALUES ('alice', 'SHA256:alice', 3)`); err != nil {
+		t.Fatalf("failed to insert stats baseline: %v", err)
+	}
+
+	if _, err := store.CreateGroup("office", "alice", "SHA256:alice"); err != nil {
+		t.Fatalf("CreateGroup() error = %v", err)
+	}
+}
+
+// userTables are the tables holding rows of a user
+var userTables = []string{"user_stats", "user_settings", "games", "game_progress", "stats_baseline", "group_members"}
+
+func TestDeleteUserData(t *testing.T) {
+	store := newTestStore(t)
+	seedUserData(t, store)
+
+	bob := testGame("2025-01-10")
+	bob.Username, bob.SSHKeyFingerprint = "bob", "SHA256:bob"
+	if err := store.RecordWin(bob); err != nil {
+		t.Fatalf("RecordWin() error = %v", err)
+	}
+
+	if err := store.DeleteUserData("alice", "SHA256:alice"); err != nil {
+		t.Fatalf("DeleteUserData() error = %v", err)
+	}
+
+	for _, table := range userTables {
+		if count := countRows(t, store, table); count != 0 {
+			t.Errorf("%d rows of alice left in %s, want 0", count, table)
+		}
+	}
+
+	groups, err := store.GetUserGroups("alice", "SHA256:alice")
+	if err != nil {
+		t.Fatalf("GetUserGroups() error = %v", err)
+	}
+
+	if len(groups) != 0 {
+		t.Errorf("alice still owns %d groups, want 0", len(groups))
+	}
+
+	if played, err := store.HasPlayedToday("bob", "SHA256:bob", "2025-01-10"); err != nil || !played {
+		t.Errorf("HasPlayedToday() of bob = %t, %v, want the game of bob kept", played, err)
+	}
+}
+
+func TestDeleteUserDataRollsBack(t *testing.T) {
+	store := newTestStore(t)
+	seedUserData(t, store)
+
+	// The last delete fails, nothing may be deleted
+	trigger := `CREATE TRIGGER fail_baseline_delete BEFORE DELETE ON stats_baseline BEGIN SELECT RAISE(ABORT, 'disk on fire'); END`
+	if _, err := store.db.Exec(trigger); err != nil {
+		t.Fatalf("failed to create trigger: %v", err)
+	}
+
+	if err := store.DeleteUserData("alice", "SHA256:alice"); err == nil {
+		t.Fatal("DeleteUserData() returned no error")
+	}
+
+	for _, table := range userTables {
+		if count := countRows(t, store, table); count == 0 {
+			t.Errorf("rows of alice in %s were deleted by a failed deletion", table)
+		}
+	}
+}
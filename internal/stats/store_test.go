@@ -0,0 +1,36 @@
+package stats
+
+import (
+	"io"
+	"path/filepath"
+	"testing"
+
+	"github.com/charmbracelet/log"
+)
+
+// testLogger discards the log output of the store
+func testLogger() *log.Logger {
+	return log.New(io.Discard)
+}
+
+// testDBPath returns the path of a new database in a temporary directory
+func testDBPath(t *testing.T) string {
+	t.Helper()
+	return filepath.Join(t.TempDir(), "stats.db")
+}
+
+// newTestStore opens a migrated store on a new database, closed at the end of the test
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := NewStore(testDBPath(t), testLogger())
+	if err != nil {
+		t.Fatalf("NewStore() error = %v", err)
+	}
+
+	t.Cleanup(func() {
+		_ = store.Close()
+	})
+
+	return store
+}
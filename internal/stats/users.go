@@ -0,0 +1,168 @@
+package stats
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrSameUser is returned when a user would be merged into themselves
+var ErrSameUser = errors.New("cannot merge a user into themselves")
+
+// User is a username and SSH key with stored data, summarized for administration
+type User struct {
+	Username          string
+	SSHKeyFingerprint string
+	GamesPlayed       int
+	GamesWon          int
+	LastPlayed        time.Time // Zero if the user never finished a game
+}
+
+// ListUsers returns every user with stored data, or only those with the
+// given username if it is not empty, ordered by username
+func (s *Store) ListUsers(username string) ([]User, error) {
+	query := `
+		SELECT u.username, u.ssh_key_fingerprint, COALESCE(s.games_played, 0), COALESCE(s.games_won, 0), s.last_played
+		FROM (
+			SELECT username, ssh_key_fingerprint FROM user_stats
+			UNION SELECT username, ssh_key_fingerprint FROM user_settings
+			UNION SELECT username, ssh_key_fingerprint FROM game_progress
+			UNION SELECT username, ssh_key_fingerprint FROM group_members
+		) u
+		LEFT JOIN user_stats s
+			ON s.username = u.username AND s.ssh_key_fingerprint = u.ssh_key_fingerprint
+		WHERE ? = '' OR u.username = ?
+		ORDER BY u.username, u.ssh_key_fingerprint
+	`
+
+	rows, err := s.db.Query(query, username, username)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	defer func() {
+		_ = rows.Close()
+	}()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		var lastPlayed sql.NullTime
+
+		if err := rows.Scan(&user.Username, &user.SSHKeyFingerprint, &user.GamesPlayed, &user.GamesWon, &lastPlayed); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+
+		if lastPlayed.Valid {
+			user.LastPlayed = lastPlayed.Time
+		}
+
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+// MergeUsers moves all data of one user to another, e.g. after they changed
+// their SSH key, and deletes the first user. Where both users have data for
+// the same puzzle date or group, the target user's data is kept.
+func (s *Store) MergeUsers(fromUsername string, fromSSHKeyFingerprint string, toUsername string, toSSHKeyFingerprint string) error {
+	if fromUsername == toUsername && fromSSHKeyFingerprint == toSSHKeyFingerprint {
+		return ErrSameUser
+	}
+
+	s.logger.Info("Merging users",
+		"from_username", fromUsername,
+		"from_ssh_key_fingerprint", fromSSHKeyFingerprint,
+		"to_username", toUsername,
+		"to_ssh_key_fingerprint", toSSHKeyFingerprint,
+	)
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	from := []any{fromUsername, fromSSHKeyFingerprint}
+	args := []any{toUsername, toSSHKeyFingerprint, fromUsername, fromSSHKeyFingerprint}
+
+	// Rows that would collide with the target's are left behind and deleted
+	for _, table := range []string{"games", "game_progress", "user_settings", "group_members"} {
+		move := `UPDATE OR IGNORE ` + table + ` SET username = ?, ssh_key_fingerprint = ? WHERE username = ? AND ssh_key_fingerprint = ?`
+		if _, err := tx.Exec(move, args...); err != nil {
+			return fmt.Errorf("failed to merge %s: %w", table, err)
+		}
+
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE username = ? AND ssh_key_fingerprint = ?`, from...); err != nil {
+			return fmt.Errorf("failed to merge %s: %w", table, err)
+		}
+	}
+
+	owner := `UPDATE user_groups SET owner_username = ?, owner_ssh_key_fingerprint = ? WHERE owner_username = ? AND owner_ssh_key_fingerprint = ?`
+	if _, err := tx.Exec(owner, args...); err != nil {
+		return fmt.Errorf("failed to merge group ownership: %w", err)
+	}
+
+	if err := mergeBaselines(tx, fromUsername, fromSSHKeyFingerprint, toUsername, toSSHKeyFingerprint); err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM user_stats WHERE username = ? AND ssh_key_fingerprint = ?`, from...); err != nil {
+		return fmt.Errorf("failed to delete merged user stats: %w", err)
+	}
+
+	if _, err := s.recomputeUserStats(tx, toUsername, toSSHKeyFingerprint); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// mergeBaselines adds the stats baseline of one user to another's
+func mergeBaselines(tx *sql.Tx, fromUsername string, fromSSHKeyFingerprint string, toUsername string, toSSHKeyFingerprint string) error {
+	// Both users played before history was recorded, the more recent streak is kept
+	sum := `
+		UPDATE stats_baseline SET
+			games_played = stats_baseline.games_played + src.games_played,
+			games_won = stats_baseline.games_won + src.games_won,
+			games_lost = stats_baseline.games_lost + src.games_lost,
+			guess_dist_1 = stats_baseline.guess_dist_1 + src.guess_dist_1,
+			guess_dist_2 = stats_baseline.guess_dist_2 + src.guess_dist_2,
+			guess_dist_3 = stats_baseline.guess_dist_3 + src.guess_dist_3,
+			guess_dist_4 = stats_baseline.guess_dist_4 + src.guess_dist_4,
+			guess_dist_5 = stats_baseline.guess_dist_5 + src.guess_dist_5,
+			guess_dist_6 = stats_baseline.guess_dist_6 + src.guess_dist_6,
+			total_guesses = stats_baseline.total_guesses + src.total_guesses,
+			max_streak = MAX(stats_baseline.max_streak, src.max_streak),
+			current_streak = CASE WHEN COALESCE(src.last_word_date, '') > COALESCE(stats_baseline.last_word_date, '')
+				THEN src.current_streak ELSE stats_baseline.current_streak END,
+			last_word_date = CASE WHEN COALESCE(src.last_word_date, '') > COALESCE(stats_baseline.last_word_date, '')
+				THEN src.last_word_date ELSE stats_baseline.last_word_date END
+		FROM (SELECT * FROM stats_baseline WHERE username = ? AND ssh_key_fingerprint = ?) AS src
+		WHERE stats_baseline.username = ? AND stats_baseline.ssh_key_fingerprint = ?
+	`
+
+	if _, err := tx.Exec(sum, fromUsername, fromSSHKeyFingerprint, toUsername, toSSHKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to merge stats baseline: %w", err)
+	}
+
+	move := `UPDATE OR IGNORE stats_baseline SET username = ?, ssh_key_fingerprint = ? WHERE username = ? AND ssh_key_fingerprint = ?`
+	if _, err := tx.Exec(move, toUsername, toSSHKeyFingerprint, fromUsername, fromSSHKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to merge stats baseline: %w", err)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM stats_baseline WHERE username = ? AND ssh_key_fingerprint = ?`, fromUsername, fromSSHKeyFingerprint); err != nil {
+		return fmt.Errorf("failed to merge stats baseline: %w", err)
+	}
+
+	return nil
+}
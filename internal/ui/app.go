@@ -1,60 +1,158 @@
 package ui
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/log"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
 	"github.com/f-gillmann/wordle-ssh/internal/stats"
 	"github.com/f-gillmann/wordle-ssh/internal/ui/models"
+	"github.com/f-gillmann/wordle-ssh/internal/ui/styles"
+	"github.com/f-gillmann/wordle-ssh/internal/wordle"
 )
 
 type AppState int
 
+// leaderboardSize is the number of entries shown per leaderboard
+const leaderboardSize = 10
+
+// practiceMode is the mode of guest games in metrics, they are never stored
+const practiceMode = "practice"
+
 const (
 	AppStateMenu AppState = iota
 	AppStateGame
+	AppStateArchive
 	AppStateStats
+	AppStateLeaderboard
+	AppStateGroups
 	AppStateAlreadyPlayed
+	AppStateSettings
 	AppStateDeleteData
 )
 
+// PuzzleProvider returns the puzzle for a date
+type PuzzleProvider interface {
+	Puzzle(date string) (*stats.DailyWord, error)
+}
+
+// Clipboard copies text to the client's clipboard
+type Clipboard interface {
+	Copy(text string)
+}
+
+// PracticeWords picks the solutions of guest practice games
+type PracticeWords interface {
+	PracticeWord() string
+}
+
 type AppModel struct {
 	menu              models.MenuModel
 	game              models.GameModel
+	archiveView       models.ArchiveModel
 	statsView         models.StatsModel
+	leaderboardView   models.LeaderboardModel
+	groupsView        models.GroupsModel
 	alreadyPlayedView models.AlreadyPlayedModel
+	settingsView      models.SettingsModel
 	deleteDataView    models.DeleteDataModel
 	state             AppState
-	targetWord        string
+	puzzles           PuzzleProvider
+	practice          PracticeWords
 	wordDate          string
+	gameMode          stats.GameMode
+	gameStartedAt     time.Time
+	gameRecorded      bool // Another session recorded the current game, its progress is no longer saved
 	username          string
 	sshKeyFingerprint string
 	statsStore        *stats.Store
-	hasPlayedToday    bool
+	clipboard         Clipboard
+	settings          *stats.UserSettings
 	hasUserData       bool
 	motd              string
+	guest             bool // Guests have no SSH key, they only practice and nothing is stored
+	shuttingDown      bool
+	timeoutWarning    *TimeoutWarningMsg
+	timedOut          *TimeoutMsg
 	logger            *log.Logger
 }
 
-func NewAppModel(targetWord string, wordDate string, username string, sshKeyFingerprint string, statsStore *stats.Store, hasPlayedToday bool, motd string, logger *log.Logger) AppModel {
+// ShutdownMsg tells the app the server is shutting down, the current game
+// can still be finished until the session is closed
+type ShutdownMsg struct{}
+
+// shutdownBanner is shown above every view once the server is shutting down
+const shutdownBanner = "Server restarting, your progress is saved. Reconnect in a minute to continue."
+
+// TimeoutWarningMsg tells the app the session is about to time out, it is
+// sent every second of the countdown and with no time remaining once the
+// session is active again
+type TimeoutWarningMsg struct {
+	Remaining time.Duration
+	Idle      bool // Whether the session is idle, otherwise it reached its maximum duration
+}
+
+// TimeoutMsg tells the app the session timed out, the current game is saved
+// before the program quits
+type TimeoutMsg struct {
+	Idle bool // Whether the session was idle, otherwise it reached its maximum duration
+}
+
+func NewAppModel(puzzles PuzzleProvider, username string, sshKeyFingerprint string, statsStore *stats.Store, clipboard Clipboard, motd string, logger *log.Logger) AppModel {
 	// Check if user has any data
 	hasUserData := false
 	if userStats, err := statsStore.GetUserStats(username, sshKeyFingerprint); err == nil && userStats.GamesPlayed > 0 {
 		hasUserData = true
 	}
 
-	return AppModel{
+	settings, err := statsStore.GetUserSettings(username, sshKeyFingerprint)
+	if err != nil {
+		logger.Error("Failed to get user settings", "error", err, "username", username)
+		settings = &stats.UserSettings{Username: username, SSHKeyFingerprint: sshKeyFingerprint}
+	}
+
+	m := AppModel{
 		menu:              models.NewMenuModel(hasUserData, motd),
 		state:             AppStateMenu,
-		targetWord:        targetWord,
-		wordDate:          wordDate,
+		puzzles:           puzzles,
 		username:          username,
 		sshKeyFingerprint: sshKeyFingerprint,
 		statsStore:        statsStore,
-		hasPlayedToday:    hasPlayedToday,
+		clipboard:         clipboard,
+		settings:          settings,
 		hasUserData:       hasUserData,
 		motd:              motd,
 		logger:            logger,
 	}
+
+	// Continue today's game if the connection dropped mid-game
+	progress, err := statsStore.GetGameProgress(username, sshKeyFingerprint, settings.Today())
+	if err != nil {
+		logger.Error("Failed to get game progress", "error", err, "username", username)
+	} else if progress != nil {
+		m.resumeGame(progress)
+	}
+
+	return m
+}
+
+// NewGuestAppModel creates the app of a guest without an SSH key, who can only
+// play practice games that are not recorded
+func NewGuestAppModel(practice PracticeWords, username string, clipboard Clipboard, motd string, logger *log.Logger) AppModel {
+	return AppModel{
+		menu:      models.NewGuestMenuModel(motd),
+		state:     AppStateMenu,
+		practice:  practice,
+		username:  username,
+		clipboard: clipboard,
+		settings:  &stats.UserSettings{Username: username},
+		motd:      motd,
+		guest:     true,
+		logger:    logger,
+	}
 }
 
 func (m AppModel) Init() tea.Cmd {
@@ -62,6 +160,52 @@ func (m AppModel) Init() tea.Cmd {
 }
 
 func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if msg, ok := msg.(models.CopyMsg); ok {
+		m.clipboard.Copy(msg.Text)
+		return m, nil
+	}
+
+	if msg, ok := msg.(models.GroupRequestMsg); ok {
+		return m, m.groupCmd(msg)
+	}
+
+	if _, ok := msg.(ShutdownMsg); ok {
+		m.logger.Info("Notifying session of shutdown", "username", m.username)
+		m.shuttingDown = true
+
+		if m.state == AppStateGame && m.game.GetState() == models.GameStatePlaying && m.game.GetGuessCount() > 0 {
+			m.saveProgress()
+		}
+
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case TimeoutWarningMsg:
+		m.timeoutWarning = &msg
+		if msg.Remaining <= 0 {
+			m.timeoutWarning = nil
+		}
+		return m, nil
+
+	case TimeoutMsg:
+		m.logger.Info("Session timed out", "username", m.username, "idle", msg.Idle)
+		m.timeoutWarning = nil
+		m.timedOut = &msg
+
+		if m.state == AppStateGame && m.game.GetState() == models.GameStatePlaying && m.game.GetGuessCount() > 0 {
+			m.saveProgress()
+		}
+
+		return m, tea.Quit
+
+	case tea.KeyMsg:
+		// A keystroke ends the idle countdown, the server confirms it on its next check
+		if m.timeoutWarning != nil && m.timeoutWarning.Idle {
+			m.timeoutWarning = nil
+		}
+	}
+
 	switch m.state {
 	case AppStateMenu:
 		var cmd tea.Cmd
@@ -69,8 +213,24 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.menu = menuModel.(models.MenuModel)
 
 		// Check if we should transition to game
-		if m.menu.GetState() == models.MenuStateGame {
-			if m.hasPlayedToday {
+		if m.menu.GetState() == models.MenuStateGame && m.guest {
+			m.startPracticeGame()
+			return m, m.game.Init()
+		} else if m.menu.GetState() == models.MenuStateGame {
+			// Today is determined by the user's timezone
+			puzzle, err := m.puzzles.Puzzle(m.settings.Today())
+			if err != nil {
+				m.logger.Error("No puzzle available to start a game", "error", err, "username", m.username)
+				m.menu = m.newMenu()
+				return m, m.menu.Init()
+			}
+
+			hasPlayed, err := m.statsStore.HasPlayedToday(m.username, m.sshKeyFingerprint, puzzle.Date)
+			if err != nil {
+				m.logger.Error("Failed to check if user played today", "error", err, "username", m.username)
+			}
+
+			if hasPlayed {
 				// User has already played today, load their result and show it
 				userStats, err := m.statsStore.GetUserStats(m.username, m.sshKeyFingerprint)
 				if err != nil {
@@ -78,15 +238,26 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					userStats = &stats.UserStats{Username: m.username, SSHKeyFingerprint: m.sshKeyFingerprint}
 				}
 
-				m.alreadyPlayedView = models.NewAlreadyPlayedModel(userStats.LastGameResult)
+				m.alreadyPlayedView = models.NewAlreadyPlayedModel(userStats.LastGameResult).
+					WithShare(puzzle.Number, m.settings.ASCIIShare)
 				m.state = AppStateAlreadyPlayed
 
 				return m, m.alreadyPlayedView.Init()
 			}
-			m.game = models.NewGameModel(m.targetWord, m.logger)
-			m.state = AppStateGame
-
+			// The game keeps this word even if the puzzle rolls over mid-game
+			m.startGame(puzzle, stats.GameModeDaily)
 			return m, m.game.Init()
+		} else if m.menu.GetState() == models.MenuStateArchive {
+			played, err := m.statsStore.GetPlayedDates(m.username, m.sshKeyFingerprint)
+			if err != nil {
+				m.logger.Error("Failed to get played dates", "error", err, "username", m.username)
+				played = map[string]bool{}
+			}
+
+			m.archiveView = models.NewArchiveModel(m.settings.Today(), played)
+			m.state = AppStateArchive
+
+			return m, m.archiveView.Init()
 		} else if m.menu.GetState() == models.MenuStateStats {
 			// Load and show user stats
 			userStats, err := m.statsStore.GetUserStats(m.username, m.sshKeyFingerprint)
@@ -96,10 +267,29 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				userStats = &stats.UserStats{Username: m.username, SSHKeyFingerprint: m.sshKeyFingerprint}
 			}
 
+			// A stored streak is broken once the user misses a day in their timezone
+			userStats.CurrentStreak = userStats.CurrentStreakOn(m.settings.Today())
+
 			m.statsView = models.NewStatsModel(userStats)
 			m.state = AppStateStats
 
 			return m, m.statsView.Init()
+		} else if m.menu.GetState() == models.MenuStateLeaderboard {
+			m.leaderboardView = models.NewLeaderboardModel(m.loadLeaderboards(), m.username)
+			m.state = AppStateLeaderboard
+
+			return m, m.leaderboardView.Init()
+		} else if m.menu.GetState() == models.MenuStateGroups {
+			m.groupsView = models.NewGroupsModel(m.username, m.sshKeyFingerprint, m.settings.Today())
+			m.state = AppStateGroups
+
+			return m, m.groupsView.Init()
+		} else if m.menu.GetState() == models.MenuStateSettings {
+			leaderboard := m.settings.OnLeaderboard(m.statsStore.LeaderboardConfig().OptIn)
+			m.settingsView = models.NewSettingsModel(m.settings.Timezone, m.settings.HardMode, leaderboard, m.settings.ASCIIShare)
+			m.state = AppStateSettings
+
+			return m, m.settingsView.Init()
 		} else if m.menu.GetState() == models.MenuStateDeleteData {
 			// Load user stats and show delete data confirmation
 			userStats, err := m.statsStore.GetUserStats(m.username, m.sshKeyFingerprint)
@@ -118,40 +308,60 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		return m, cmd
 
+	case AppStateArchive:
+		var cmd tea.Cmd
+		archiveModel, cmd := m.archiveView.Update(msg)
+		m.archiveView = archiveModel.(models.ArchiveModel)
+
+		if m.archiveView.GetState() == models.ArchiveStatePlay {
+			date := m.archiveView.GetSelectedDate()
+
+			puzzle, err := m.puzzles.Puzzle(date)
+			if err != nil {
+				m.logger.Error("Failed to load archive puzzle", "error", err, "date", date)
+				m.archiveView = m.archiveView.WithMessage("This puzzle is not available")
+				return m, nil
+			}
+
+			m.startGame(puzzle, stats.GameModeArchive)
+			return m, m.game.Init()
+		} else if m.archiveView.GetState() == models.ArchiveStateMenu {
+			m.menu = m.newMenu()
+			m.state = AppStateMenu
+			return m, m.menu.Init()
+		}
+
+		return m, cmd
+
 	case AppStateGame:
 		var cmd tea.Cmd
+		previousState := m.game.GetState()
+		previousGuesses := m.game.GetGuessCount()
 		gameModel, cmd := m.game.Update(msg)
 		m.game = gameModel.(models.GameModel)
+		gameEnded := previousState == models.GameStatePlaying && m.game.GetState() != models.GameStatePlaying
 
-		// Check if game ended and record stats
-		if m.game.GetState() == models.GameStateWon {
-			// Record win with number of guesses and game result
-			guesses := m.game.GetGuessCount()
-			gameResultJSON := m.game.GetGameResultJSON()
+		// Save every accepted guess so the game survives a dropped connection
+		if m.game.GetState() == models.GameStatePlaying && m.game.GetGuessCount() > previousGuesses {
+			m.saveProgress()
+		}
 
-			if err := m.statsStore.RecordWin(m.username, m.sshKeyFingerprint, guesses, m.wordDate, gameResultJSON); err != nil {
-				m.logger.Error("Failed to record win", "error", err, "username", m.username)
-			} else {
-				// Mark that user has played today
-				m.hasPlayedToday = true
-				m.hasUserData = true
+		// Check if game ended and record stats, practice games are not recorded
+		if m.guest {
+			if gameEnded {
+				m.logger.Info("Finished practice game", "username", m.username, "won", m.game.GetState() == models.GameStateWon)
 			}
-		} else if m.game.GetState() == models.GameStateLost {
+		} else if gameEnded && m.game.GetState() == models.GameStateWon {
+			// Record win with number of guesses and game result
+			m.recordResult("win", m.statsStore.RecordWin(m.finishedGame()))
+		} else if gameEnded && m.game.GetState() == models.GameStateLost {
 			// Record loss with game result
-			gameResultJSON := m.game.GetGameResultJSON()
-
-			if err := m.statsStore.RecordLoss(m.username, m.sshKeyFingerprint, m.wordDate, gameResultJSON); err != nil {
-				m.logger.Error("Failed to record loss", "error", err, "username", m.username)
-			} else {
-				// Mark that user has played today
-				m.hasPlayedToday = true
-				m.hasUserData = true
-			}
+			m.recordResult("loss", m.statsStore.RecordLoss(m.finishedGame()))
 		}
 
 		// Check if we should return to menu or quit
 		if m.game.GetState() == models.GameStateMenu {
-			m.menu = models.NewMenuModel(m.hasUserData, m.motd)
+			m.menu = m.newMenu()
 			m.state = AppStateMenu
 			return m, m.menu.Init()
 		} else if m.game.GetState() == models.GameStateQuit {
@@ -168,7 +378,33 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Check if any key was pressed to return to menu
 		if _, ok := msg.(tea.KeyMsg); ok {
 			// Any key returns to menu
-			m.menu = models.NewMenuModel(m.hasUserData, m.motd)
+			m.menu = m.newMenu()
+			m.state = AppStateMenu
+			return m, m.menu.Init()
+		}
+
+		return m, cmd
+
+	case AppStateLeaderboard:
+		var cmd tea.Cmd
+		leaderboardModel, cmd := m.leaderboardView.Update(msg)
+		m.leaderboardView = leaderboardModel.(models.LeaderboardModel)
+
+		if m.leaderboardView.GetState() == models.LeaderboardStateMenu {
+			m.menu = m.newMenu()
+			m.state = AppStateMenu
+			return m, m.menu.Init()
+		}
+
+		return m, cmd
+
+	case AppStateGroups:
+		var cmd tea.Cmd
+		groupsModel, cmd := m.groupsView.Update(msg)
+		m.groupsView = groupsModel.(models.GroupsModel)
+
+		if m.groupsView.GetState() == models.GroupsStateMenu {
+			m.menu = m.newMenu()
 			m.state = AppStateMenu
 			return m, m.menu.Init()
 		}
@@ -180,11 +416,11 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		alreadyPlayedModel, cmd := m.alreadyPlayedView.Update(msg)
 		m.alreadyPlayedView = alreadyPlayedModel.(models.AlreadyPlayedModel)
 
-		// If quit command was issued (Ctrl+C), pass it through
+		// If quit (Ctrl+C) or copy was issued, pass it through
 		if cmd != nil {
-			// Check if it's a quit command by checking the message
+			// Check if it's a quit or copy command by checking the message
 			if keyMsg, ok := msg.(tea.KeyMsg); ok {
-				if keyMsg.String() == "ctrl+c" || keyMsg.String() == "q" {
+				if keyMsg.String() == "ctrl+c" || keyMsg.String() == "q" || keyMsg.String() == "c" {
 					return m, cmd
 				}
 			}
@@ -193,7 +429,45 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		// Check if any key was pressed to return to menu
 		if _, ok := msg.(tea.KeyMsg); ok {
 			// Any other key returns to menu
-			m.menu = models.NewMenuModel(m.hasUserData, m.motd)
+			m.menu = m.newMenu()
+			m.state = AppStateMenu
+			return m, m.menu.Init()
+		}
+
+		return m, cmd
+
+	case AppStateSettings:
+		var cmd tea.Cmd
+		settingsModel, cmd := m.settingsView.Update(msg)
+		m.settingsView = settingsModel.(models.SettingsModel)
+
+		// Persist the settings once the user saved them
+		optIn := m.statsStore.LeaderboardConfig().OptIn
+		leaderboardChanged := m.settingsView.GetLeaderboard() != m.settings.OnLeaderboard(optIn)
+		if m.settingsView.GetState() == models.SettingsStateSaved &&
+			(m.settingsView.GetTimezone() != m.settings.Timezone || m.settingsView.GetHardMode() != m.settings.HardMode ||
+				m.settingsView.GetASCIIShare() != m.settings.ASCIIShare || leaderboardChanged) {
+			settings := *m.settings
+			settings.Timezone = m.settingsView.GetTimezone()
+			settings.HardMode = m.settingsView.GetHardMode()
+			settings.ASCIIShare = m.settingsView.GetASCIIShare()
+
+			// Keep following the server default unless the user changed it
+			if leaderboardChanged {
+				leaderboard := m.settingsView.GetLeaderboard()
+				settings.ShowOnLeaderboard = &leaderboard
+			}
+
+			if err := m.statsStore.SaveUserSettings(&settings); err != nil {
+				m.logger.Error("Failed to save user settings", "error", err, "username", m.username)
+			} else {
+				m.logger.Info("Updated user settings", "username", m.username, "timezone", settings.Timezone, "hard_mode", settings.HardMode)
+				m.settings = &settings
+			}
+		}
+
+		if m.settingsView.GetState() == models.SettingsStateMenu {
+			m.menu = m.newMenu()
 			m.state = AppStateMenu
 			return m, m.menu.Init()
 		}
@@ -212,15 +486,15 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.logger.Error("Failed to delete user data", "error", err, "username", m.username)
 			} else {
 				m.logger.Info("Successfully deleted user data", "username", m.username)
-				// Reset hasPlayedToday and hasUserData flags since data is deleted
-				m.hasPlayedToday = false
+				// Reset hasUserData flag and settings since data is deleted
 				m.hasUserData = false
+				m.settings = &stats.UserSettings{Username: m.username, SSHKeyFingerprint: m.sshKeyFingerprint}
 			}
 		}
 
 		// Check if we should return to menu
 		if m.deleteDataView.GetState() == models.DeleteDataStateMenu {
-			m.menu = models.NewMenuModel(m.hasUserData, m.motd)
+			m.menu = m.newMenu()
 			m.state = AppStateMenu
 			return m, m.menu.Init()
 		}
@@ -232,16 +506,216 @@ func (m AppModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	}
 }
 
+// newMenu creates the main menu to return to
+func (m AppModel) newMenu() models.MenuModel {
+	if m.guest {
+		return models.NewGuestMenuModel(m.motd)
+	}
+
+	return models.NewMenuModel(m.hasUserData, m.motd)
+}
+
+// loadLeaderboards loads the entries of every leaderboard tab
+func (m AppModel) loadLeaderboards() models.LeaderboardData {
+	config := m.statsStore.LeaderboardConfig()
+	today := m.settings.Today()
+
+	data := models.LeaderboardData{
+		Date:     today,
+		MinGames: config.MinGames,
+		Visible:  m.settings.OnLeaderboard(config.OptIn),
+	}
+
+	var err error
+	if data.Today, err = m.statsStore.DailyLeaderboard(today, leaderboardSize); err != nil {
+		m.logger.Error("Failed to get daily leaderboard", "error", err)
+	}
+
+	if data.WinRate, err = m.statsStore.WinRateLeaderboard(leaderboardSize); err != nil {
+		m.logger.Error("Failed to get win rate leaderboard", "error", err)
+	}
+
+	if data.CurrentStreaks, err = m.statsStore.StreakLeaderboard(false, leaderboardSize); err != nil {
+		m.logger.Error("Failed to get current streak leaderboard", "error", err)
+	}
+
+	if data.MaxStreaks, err = m.statsStore.StreakLeaderboard(true, leaderboardSize); err != nil {
+		m.logger.Error("Failed to get max streak leaderboard", "error", err)
+	}
+
+	return data
+}
+
+// startGame starts a game for a puzzle, continuing it if it was left unfinished
+func (m *AppModel) startGame(puzzle *stats.DailyWord, mode stats.GameMode) {
+	progress, err := m.statsStore.GetGameProgress(m.username, m.sshKeyFingerprint, puzzle.Date)
+	if err != nil {
+		m.logger.Error("Failed to get game progress", "error", err, "username", m.username)
+	}
+
+	if progress != nil {
+		m.resumeGame(progress)
+		return
+	}
+
+	m.wordDate = puzzle.Date
+	m.gameMode = mode
+	m.game = models.NewGameModel(puzzle.Solution, m.settings.HardMode, m.logger).
+		WithShare(puzzle.Number, m.settings.ASCIIShare)
+	m.gameStartedAt = time.Now()
+	m.gameRecorded = false
+	m.state = AppStateGame
+
+	metrics.GamesStarted.WithLabelValues(string(mode)).Inc()
+}
+
+// startPracticeGame starts a guest game with a random past word
+func (m *AppModel) startPracticeGame() {
+	m.wordDate = ""
+	m.game = models.NewGameModel(m.practice.PracticeWord(), false, m.logger).
+		AsPractice(m.settings.ASCIIShare)
+	m.gameStartedAt = time.Now()
+	m.state = AppStateGame
+
+	metrics.GamesStarted.WithLabelValues(practiceMode).Inc()
+}
+
+// resumeGame continues an unfinished game
+func (m *AppModel) resumeGame(progress *stats.GameProgress) {
+	m.logger.Info("Resuming game", "username", m.username, "word_date", progress.WordDate, "guesses", progress.Game.GuessCount())
+
+	// Share the number of the stored puzzle, like a game started from it
+	number := wordle.PuzzleNumber(progress.WordDate)
+	if puzzle, err := m.puzzles.Puzzle(progress.WordDate); err != nil {
+		m.logger.Error("Failed to get puzzle of resumed game", "error", err, "date", progress.WordDate)
+	} else if puzzle.Number > 0 {
+		number = puzzle.Number
+	}
+
+	m.wordDate = progress.WordDate
+	m.gameMode = progress.Mode
+	m.game = models.RestoreGameModel(progress.Game, m.logger).
+		WithShare(number, m.settings.ASCIIShare)
+	m.gameStartedAt = progress.StartedAt
+	m.gameRecorded = false
+	m.state = AppStateGame
+}
+
+// saveProgress saves the current game so it can be resumed, practice games are not saved
+func (m *AppModel) saveProgress() {
+	if m.guest || m.gameRecorded {
+		return
+	}
+
+	progress := &stats.GameProgress{
+		Username:          m.username,
+		SSHKeyFingerprint: m.sshKeyFingerprint,
+		WordDate:          m.wordDate,
+		Mode:              m.gameMode,
+		Game:              m.game.GetGame(),
+		StartedAt:         m.gameStartedAt,
+	}
+
+	err := m.statsStore.SaveGameProgress(progress)
+	switch {
+	case errors.Is(err, stats.ErrAlreadyRecorded):
+		// Another session of the same user finished this puzzle first
+		m.logger.Warn("Game already recorded, no longer saving progress", "username", m.username, "word_date", m.wordDate)
+		m.gameRecorded = true
+	case err != nil:
+		m.logger.Error("Failed to save game progress", "error", err, "username", m.username)
+	}
+}
+
+// recordResult logs the outcome of recording a finished game
+func (m *AppModel) recordResult(outcome string, err error) {
+	switch {
+	case errors.Is(err, stats.ErrAlreadyRecorded):
+		// Another session of the same user finished this puzzle first
+		m.logger.Warn("Game already recorded", "outcome", outcome, "username", m.username, "word_date", m.wordDate)
+	case err != nil:
+		m.logger.Error("Failed to record "+outcome, "error", err, "username", m.username)
+	default:
+		m.hasUserData = true
+	}
+}
+
+// finishedGame builds the stats record for the current game
+func (m AppModel) finishedGame() *stats.Game {
+	return &stats.Game{
+		Username:          m.username,
+		SSHKeyFingerprint: m.sshKeyFingerprint,
+		WordDate:          m.wordDate,
+		Mode:              m.gameMode,
+		HardMode:          m.game.IsHardMode(),
+		Guesses:           m.game.GetGuessCount(),
+		GameResult:        m.game.GetGameResultJSON(),
+		StartedAt:         m.gameStartedAt,
+	}
+}
+
 func (m AppModel) View() string {
+	if m.timedOut != nil {
+		return styles.ErrorStyle.Render(timeoutBanner(m.timedOut.Idle)) + "\n"
+	}
+
+	view := m.stateView()
+
+	if m.timeoutWarning != nil {
+		view = styles.ErrorStyle.Render(timeoutWarningBanner(*m.timeoutWarning)) + "\n\n" + view
+	}
+
+	if m.shuttingDown {
+		view = styles.ErrorStyle.Render(shutdownBanner) + "\n\n" + view
+	}
+
+	return view
+}
+
+// timeoutWarningBanner returns the countdown shown before a session times out
+func timeoutWarningBanner(warning TimeoutWarningMsg) string {
+	seconds := int(warning.Remaining.Round(time.Second).Seconds())
+	if warning.Idle {
+		return fmt.Sprintf("Disconnecting in %ds due to inactivity, press any key to stay.", seconds)
+	}
+
+	return fmt.Sprintf("Session time limit reached, disconnecting in %ds. Your progress is saved.", seconds)
+}
+
+// timeoutBanner returns the message shown when a session timed out
+func timeoutBanner(idle bool) string {
+	if idle {
+		return "Disconnected due to inactivity, your progress is saved."
+	}
+
+	return "Session time limit reached, your progress is saved."
+}
+
+// stateView renders the view of the current state
+func (m AppModel) stateView() string {
 	switch m.state {
 	case AppStateMenu:
 		return m.menu.View()
 	case AppStateGame:
+		if m.guest {
+			return styles.HelpStyle.Render("Practice game, not recorded") + "\n\n" + m.game.View()
+		}
+		if m.gameMode == stats.GameModeArchive {
+			return styles.HelpStyle.Render("Archive puzzle: "+m.wordDate) + "\n\n" + m.game.View()
+		}
 		return m.game.View()
+	case AppStateArchive:
+		return m.archiveView.View()
 	case AppStateStats:
 		return m.statsView.View()
+	case AppStateLeaderboard:
+		return m.leaderboardView.View()
+	case AppStateGroups:
+		return m.groupsView.View()
 	case AppStateAlreadyPlayed:
 		return m.alreadyPlayedView.View()
+	case AppStateSettings:
+		return m.settingsView.View()
 	case AppStateDeleteData:
 		return m.deleteDataView.View()
 	default:
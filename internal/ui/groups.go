@@ -0,0 +1,127 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+	"github.com/f-gillmann/wordle-ssh/internal/ui/models"
+)
+
+// groupLeaderboardSize is the number of entries shown per group leaderboard
+const groupLeaderboardSize = 25
+
+// groupCmd returns a command that performs a group action of the groups view
+// and answers with its result
+func (m AppModel) groupCmd(request models.GroupRequestMsg) tea.Cmd {
+	return func() tea.Msg {
+		switch request.Action {
+		case models.GroupActionOpen:
+			return m.openGroup(request.GroupID, "")
+
+		case models.GroupActionCreate, models.GroupActionJoin:
+			var group *stats.Group
+			var err error
+
+			if request.Action == models.GroupActionCreate {
+				group, err = m.statsStore.CreateGroup(request.Input, m.username, m.sshKeyFingerprint)
+			} else {
+				group, err = m.statsStore.JoinGroup(request.Input, m.username, m.sshKeyFingerprint)
+			}
+
+			if err != nil {
+				if !errors.Is(err, stats.ErrGroupNotFound) && !errors.Is(err, stats.ErrInvalidGroupName) {
+					m.logger.Error("Failed to create or join group", "error", err, "username", m.username)
+				}
+
+				return models.GroupErrorMsg{Err: err}
+			}
+
+			notice := fmt.Sprintf("You joined %s.", group.Name)
+			if request.Action == models.GroupActionCreate {
+				notice = fmt.Sprintf("Group created! Share the invite code %s with your team.", group.InviteCode)
+			}
+
+			return m.openGroup(group.ID, notice)
+
+		case models.GroupActionRemoveMember:
+			if err := m.statsStore.RemoveGroupMember(request.GroupID, m.username, m.sshKeyFingerprint, request.Member); err != nil {
+				return models.GroupErrorMsg{Err: err}
+			}
+
+			return m.openGroup(request.GroupID, fmt.Sprintf("Removed %s from the group.", request.Member.Username))
+
+		case models.GroupActionRotateInvite:
+			code, err := m.statsStore.RotateInviteCode(request.GroupID, m.username, m.sshKeyFingerprint)
+			if err != nil {
+				m.logger.Error("Failed to rotate invite code", "error", err, "group_id", request.GroupID)
+				return models.GroupErrorMsg{Err: err}
+			}
+
+			return m.openGroup(request.GroupID, fmt.Sprintf("New invite code: %s, the old code no longer works.", code))
+
+		case models.GroupActionLeave:
+			if err := m.statsStore.LeaveGroup(request.GroupID, m.username, m.sshKeyFingerprint); err != nil {
+				m.logger.Error("Failed to leave group", "error", err, "group_id", request.GroupID)
+				return models.GroupErrorMsg{Err: err}
+			}
+		}
+
+		return m.loadGroups(nil)
+	}
+}
+
+// loadGroups loads the groups the user is a member of, err is shown above the list
+func (m AppModel) loadGroups(err error) models.GroupsLoadedMsg {
+	groups, loadErr := m.statsStore.GetUserGroups(m.username, m.sshKeyFingerprint)
+	if loadErr != nil {
+		m.logger.Error("Failed to get groups", "error", loadErr, "username", m.username)
+	}
+
+	return models.GroupsLoadedMsg{Groups: groups, Err: err}
+}
+
+// openGroup loads a group with its members, games and leaderboards, the list
+// of groups is shown instead if the group can't be loaded
+func (m AppModel) openGroup(groupID int64, notice string) tea.Msg {
+	group, err := m.statsStore.GetGroup(groupID)
+	if err != nil {
+		m.logger.Error("Failed to get group", "error", err, "group_id", groupID)
+		return m.loadGroups(err)
+	}
+
+	today := m.settings.Today()
+	msg := models.GroupLoadedMsg{
+		Group:        group,
+		Leaderboards: models.LeaderboardData{Date: today, MinGames: 1, Visible: true},
+		Notice:       notice,
+	}
+
+	if msg.Members, err = m.statsStore.GetGroupMembers(groupID); err != nil {
+		m.logger.Error("Failed to get group members", "error", err, "group_id", groupID)
+	}
+
+	if msg.Games, err = m.statsStore.GetGroupGames(groupID, today); err != nil {
+		m.logger.Error("Failed to get group games", "error", err, "group_id", groupID)
+	}
+
+	data := &msg.Leaderboards
+	if data.Today, err = m.statsStore.GroupDailyLeaderboard(groupID, today, groupLeaderboardSize); err != nil {
+		m.logger.Error("Failed to get group daily leaderboard", "error", err, "group_id", groupID)
+	}
+
+	if data.WinRate, err = m.statsStore.GroupWinRateLeaderboard(groupID, groupLeaderboardSize); err != nil {
+		m.logger.Error("Failed to get group win rate leaderboard", "error", err, "group_id", groupID)
+	}
+
+	if data.CurrentStreaks, err = m.statsStore.GroupStreakLeaderboard(groupID, false, groupLeaderboardSize); err != nil {
+		m.logger.Error("Failed to get group streak leaderboard", "error", err, "group_id", groupID)
+	}
+
+	if data.MaxStreaks, err = m.statsStore.GroupStreakLeaderboard(groupID, true, groupLeaderboardSize); err != nil {
+		m.logger.Error("Failed to get group streak leaderboard", "error", err, "group_id", groupID)
+	}
+
+	return msg
+}
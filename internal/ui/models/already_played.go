@@ -1,44 +1,42 @@
 package models
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
 	"github.com/f-gillmann/wordle-ssh/internal/ui/styles"
 )
 
 type AlreadyPlayedModel struct {
-	gameResult string
-	won        bool
-	guesses    int
+	result       *engine.Result
+	puzzleNumber int
+	asciiShare   bool
+	copied       bool
 }
 
 func NewAlreadyPlayedModel(gameResultJSON string) AlreadyPlayedModel {
-	won := false
-	guesses := 0
-
+	var result *engine.Result
 	if gameResultJSON != "" {
-		var result struct {
-			W bool     `json:"w"`
-			G []string `json:"g"`
-		}
-
-		if err := json.Unmarshal([]byte(gameResultJSON), &result); err == nil {
-			won = result.W
-			guesses = len(result.G)
+		if parsed, err := engine.ParseResult(gameResultJSON); err == nil {
+			result = parsed
 		}
 	}
 
 	return AlreadyPlayedModel{
-		gameResult: gameResultJSON,
-		won:        won,
-		guesses:    guesses,
+		result: result,
 	}
 }
 
+// WithShare sets the puzzle number and format of the shared result
+func (m AlreadyPlayedModel) WithShare(puzzleNumber int, ascii bool) AlreadyPlayedModel {
+	m.puzzleNumber = puzzleNumber
+	m.asciiShare = ascii
+	return m
+}
+
 func (m AlreadyPlayedModel) Init() tea.Cmd {
 	return nil
 }
@@ -49,6 +47,11 @@ func (m AlreadyPlayedModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch msg.String() {
 		case "ctrl+c", "q":
 			return m, tea.Quit
+		case "c":
+			if m.CanShare() {
+				m.copied = true
+				return m, copyToClipboard(m.GetShareText())
+			}
 		case "esc", "enter":
 			// Return to menu
 			return m, nil
@@ -68,78 +71,74 @@ func (m AlreadyPlayedModel) View() string {
 	s.WriteString(titleStyle.Render("You've already played today!"))
 	s.WriteString("\n")
 
-	// Parse and display the game result
-	if m.gameResult != "" {
-		var result struct {
-			W bool     `json:"w"`
-			G []string `json:"g"`
-		}
-
-		if err := json.Unmarshal([]byte(m.gameResult), &result); err == nil && len(result.G) > 0 {
-			won := result.W
-			guesses := result.G
-
-			// Render the squares from compact format
-			for _, guess := range guesses {
-				var tiles []string
-				// Parse compact format: "L1S1L2S2L3S3L4S4L5S5"
-				for i := 0; i < len(guess); i += 2 {
-					if i+1 >= len(guess) {
-						break
-					}
-
-					state := guess[i+1]
-
-					var style lipgloss.Style
-					switch state {
-					case 'c':
-						style = styles.TileStyleCorrect
-					case 'p':
-						style = styles.TileStylePresent
-					case 'a':
-						style = styles.TileStyleAbsent
-					default:
-						style = styles.TileStyleEmpty
-					}
-
-					tiles = append(tiles, style.Render("*"))
-				}
-
-				if len(tiles) > 0 {
-					s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tiles...))
-					s.WriteString("\n")
-				}
+	// Display the game result
+	if m.result != nil && len(m.result.Guesses) > 0 {
+		// Render the squares without revealing the letters
+		feedback := m.result.Feedback()
+		for _, row := range feedback {
+			var tiles []string
+			for _, letter := range row {
+				tiles = append(tiles, tileStyle(letter.State).Render("*"))
 			}
 
-			// Render empty rows for remaining guesses
-			remainingGuesses := MaxGuesses - len(guesses)
-			for i := 0; i < remainingGuesses; i++ {
-				var emptyTiles []string
-				for j := 0; j < WordLength; j++ {
-					emptyTiles = append(emptyTiles, styles.TileStyleEmpty.Render(" "))
-				}
-				s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, emptyTiles...))
+			if len(tiles) > 0 {
+				s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, tiles...))
 				s.WriteString("\n")
 			}
+		}
 
+		// Render empty rows for remaining guesses
+		remainingGuesses := engine.MaxGuesses - len(feedback)
+		for i := 0; i < remainingGuesses; i++ {
+			var emptyTiles []string
+			for j := 0; j < engine.WordLength; j++ {
+				emptyTiles = append(emptyTiles, styles.TileStyleEmpty.Render(" "))
+			}
+			s.WriteString(lipgloss.JoinHorizontal(lipgloss.Top, emptyTiles...))
 			s.WriteString("\n")
+		}
 
-			if won {
-				s.WriteString(styles.SuccessStyle.Render(fmt.Sprintf("You won in %d guesses!", len(guesses))))
-			} else {
-				s.WriteString(styles.ErrorStyle.Render("You didn't get it this time."))
-			}
+		s.WriteString("\n")
+
+		if m.result.Won {
+			s.WriteString(styles.SuccessStyle.Render(fmt.Sprintf("You won in %d guesses!", len(feedback))))
+		} else {
+			s.WriteString(styles.ErrorStyle.Render("You didn't get it this time."))
+		}
+
+		if m.result.HardMode {
+			s.WriteString("\n")
+			s.WriteString(styles.HelpStyle.Render("Played in hard mode"))
 		}
 	} else {
 		s.WriteString("Come back tomorrow to play again!")
 	}
 
 	s.WriteString("\n\n")
-	s.WriteString(styles.HelpStyle.Render("Any key to return | Q/Ctrl+C to quit"))
+	if m.CanShare() {
+		s.WriteString(renderCopied(m.copied, m.GetShareText()))
+		s.WriteString(styles.HelpStyle.Render("C to copy result | Any other key to return | Q/Ctrl+C to quit"))
+	} else {
+		s.WriteString(styles.HelpStyle.Render("Any key to return | Q/Ctrl+C to quit"))
+	}
 
 	return s.String()
 }
 
+// CanShare returns whether there is a result to share
+func (m AlreadyPlayedModel) CanShare() bool {
+	return m.result != nil && len(m.result.Guesses) > 0
+}
+
+// GetShareText returns the result in the standard shareable format
+func (m AlreadyPlayedModel) GetShareText() string {
+	if m.result == nil {
+		return ""
+	}
+
+	return m.result.ShareText(m.puzzleNumber, m.asciiShare)
+}
+
 func (m AlreadyPlayedModel) GetShouldReturnToMenu() bool {
 	return false
 }
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+	"github.com/f-gillmann/wordle-ssh/internal/ui/styles"
+	"github.com/f-gillmann/wordle-ssh/internal/wordle"
+)
+
+type ArchiveState int
+
+const (
+	ArchiveStateBrowsing ArchiveState = iota
+	ArchiveStatePlay
+	ArchiveStateMenu
+)
+
+type ArchiveModel struct {
+	played   map[string]bool // Puzzle date -> won
+	first    time.Time
+	last     time.Time
+	selected time.Time
+	state    ArchiveState
+	message  string
+}
+
+// NewArchiveModel creates a calendar of past puzzles up to the day before today
+func NewArchiveModel(today string, played map[string]bool) ArchiveModel {
+	first, _ := time.Parse(stats.DateFormat, wordle.FirstPuzzleDate)
+
+	last, err := time.Parse(stats.DateFormat, today)
+	if err != nil {
+		last = time.Now().UTC().Truncate(24 * time.Hour)
+	}
+	last = last.AddDate(0, 0, -1)
+
+	return ArchiveModel{
+		played:   played,
+		first:    first,
+		last:     last,
+		selected: last,
+		state:    ArchiveStateBrowsing,
+	}
+}
+
+func (m ArchiveModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ArchiveModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.state != ArchiveStateBrowsing {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.message = ""
+
+		switch msg.String() {
+		case "ctrl+c":
+			return m, tea.Quit
+
+		case "esc", "q":
+			m.state = ArchiveStateMenu
+			return m, nil
+
+		case "left", "h":
+			m.move(0, -1)
+		case "right", "l":
+			m.move(0, 1)
+		case "up", "k":
+			m.move(0, -7)
+		case "down", "j":
+			m.move(0, 7)
+		case "[", "pgup":
+			m.move(-1, 0)
+		case "]", "pgdown":
+			m.move(1, 0)
+
+		case "enter":
+			if _, ok := m.played[m.GetSelectedDate()]; ok {
+				m.message = "You already played this puzzle"
+				return m, nil
+			}
+
+			m.state = ArchiveStatePlay
+			return m, nil
+		}
+	}
+
+	return m, nil
+}
+
+// move shifts the selection and clamps it to the playable range
+func (m *ArchiveModel) move(months int, days int) {
+	selected := m.selected.AddDate(0, months, days)
+
+	if selected.Before(m.first) {
+		selected = m.first
+	}
+
+	if selected.After(m.last) {
+		selected = m.last
+	}
+
+	m.selected = selected
+}
+
+func (m ArchiveModel) View() string {
+	var s strings.Builder
+
+	s.WriteString(styles.MenuTitleStyle.Render(m.selected.Format("January 2006")))
+	s.WriteString("\n\n")
+	s.WriteString(styles.HelpStyle.Render(" Mo  Tu  We  Th  Fr  Sa  Su"))
+	s.WriteString("\n")
+
+	monthStart := time.Date(m.selected.Year(), m.selected.Month(), 1, 0, 0, 0, 0, time.UTC)
+	// Weeks start on Monday
+	offset := (int(monthStart.Weekday()) + 6) % 7
+
+	var grid strings.Builder
+	grid.WriteString(strings.Repeat("    ", offset))
+
+	for day := monthStart; day.Month() == monthStart.Month(); day = day.AddDate(0, 0, 1) {
+		grid.WriteString(m.renderDay(day))
+
+		if day.Weekday() == time.Sunday {
+			grid.WriteString("\n")
+		} else {
+			grid.WriteString(" ")
+		}
+	}
+
+	s.WriteString(strings.TrimRight(grid.String(), " \n"))
+	s.WriteString("\n\n")
+	s.WriteString(fmt.Sprintf("%s  %s  %s\n\n",
+		styles.CalendarWonStyle.Render("■ won"),
+		styles.CalendarLostStyle.Render("■ lost"),
+		styles.CalendarOpenStyle.Render("■ not played"),
+	))
+
+	date := m.GetSelectedDate()
+	status := "not played"
+	if won, ok := m.played[date]; ok {
+		status = "lost"
+		if won {
+			status = "won"
+		}
+	}
+
+	s.WriteString(fmt.Sprintf("%s: %s\n", date, status))
+
+	if m.message != "" {
+		s.WriteString(styles.ErrorStyle.Render(m.message))
+		s.WriteString("\n")
+	}
+
+	s.WriteString("\n")
+	s.WriteString(styles.HelpStyle.Render("Arrows/hjkl to move | [/] to change month | Enter to play | Esc to menu"))
+
+	return s.String()
+}
+
+// renderDay renders a single calendar cell
+func (m ArchiveModel) renderDay(day time.Time) string {
+	label := fmt.Sprintf("%3d", day.Day())
+	date := day.Format(stats.DateFormat)
+
+	var style lipgloss.Style
+	switch won, played := m.played[date]; {
+	case day.Equal(m.selected):
+		style = styles.CalendarSelectedStyle
+	case day.Before(m.first) || day.After(m.last):
+		style = styles.CalendarDisabledStyle
+	case played && won:
+		style = styles.CalendarWonStyle
+	case played:
+		style = styles.CalendarLostStyle
+	default:
+		style = styles.CalendarOpenStyle
+	}
+
+	return style.Render(label)
+}
+
+func (m ArchiveModel) GetState() ArchiveState {
+	return m.state
+}
+
+// GetSelectedDate returns the selected puzzle date
+func (m ArchiveModel) GetSelectedDate() string {
+	return m.selected.Format(stats.DateFormat)
+}
+
+// WithMessage returns the model back in browsing state showing a message
+func (m ArchiveModel) WithMessage(message string) ArchiveModel {
+	m.state = ArchiveStateBrowsing
+	m.message = message
+	return m
+}
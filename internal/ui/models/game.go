@@ -1,20 +1,16 @@
 package models
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
+	"github.com/f-gillmann/wordle-ssh/internal/metrics"
 	"github.com/f-gillmann/wordle-ssh/internal/ui/styles"
-	"github.com/f-gillmann/wordle-ssh/internal/wordle"
-)
-
-const (
-	MaxGuesses = 6
-	WordLength = 5
 )
 
 type GameState int
@@ -27,45 +23,55 @@ const (
 	GameStateQuit
 )
 
-type GuessResult struct {
-	Letter string
-	State  LetterState
-}
-
-type LetterState int
-
-const (
-	LetterStateCorrect LetterState = iota // Green - correct position
-	LetterStatePresent                    // Yellow - in word but wrong position
-	LetterStateAbsent                     // Gray - not in word
-)
-
+// GameModel renders an engine.Game and feeds it the user's input
 type GameModel struct {
-	targetWord   string
-	guesses      []string
+	game         *engine.Game
 	currentGuess string
-	guessResults [][]GuessResult
 	state        GameState
 	errorMessage string
-	letterMap    map[rune]LetterState
 	invalidWord  bool
+	puzzleNumber int
+	asciiShare   bool
+	practice     bool // Practice games have no puzzle number, their share is labeled instead
+	copied       bool
 	logger       *log.Logger
 }
 
-func NewGameModel(targetWord string, logger *log.Logger) GameModel {
-	logger.Debug("Creating new game model", "targetWord", targetWord)
+func NewGameModel(targetWord string, hardMode bool, logger *log.Logger) GameModel {
+	logger.Debug("Creating new game model", "targetWord", targetWord, "hardMode", hardMode)
 
 	return GameModel{
-		targetWord:   strings.ToLower(targetWord),
-		guesses:      []string{},
-		currentGuess: "",
-		guessResults: [][]GuessResult{},
-		state:        GameStatePlaying,
-		letterMap:    make(map[rune]LetterState),
-		logger:       logger,
+		game:   engine.New(targetWord, hardMode),
+		state:  GameStatePlaying,
+		logger: logger,
 	}
 }
 
+// RestoreGameModel creates a game model for a game that is already in progress
+func RestoreGameModel(game *engine.Game, logger *log.Logger) GameModel {
+	logger.Debug("Restoring game model", "guesses", game.GuessCount(), "hardMode", game.HardMode())
+
+	return GameModel{
+		game:   game,
+		state:  GameStatePlaying,
+		logger: logger,
+	}
+}
+
+// WithShare sets the puzzle number and format of the result shared once the game is over
+func (m GameModel) WithShare(puzzleNumber int, ascii bool) GameModel {
+	m.puzzleNumber = puzzleNumber
+	m.asciiShare = ascii
+	return m
+}
+
+// AsPractice marks the game as a practice game, shared as "Wordle practice"
+func (m GameModel) AsPractice(ascii bool) GameModel {
+	m.practice = true
+	m.asciiShare = ascii
+	return m
+}
+
 func (m GameModel) Init() tea.Cmd {
 	return nil
 }
@@ -73,6 +79,13 @@ func (m GameModel) Init() tea.Cmd {
 func (m GameModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		// Letters are only guesses while playing, afterwards C copies the result
+		if msg.String() == "c" && (m.state == GameStateWon || m.state == GameStateLost) {
+			m.logger.Debug("User copied game result")
+			m.copied = true
+			return m, copyToClipboard(m.GetShareText())
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			m.logger.Debug("User quit game")
@@ -92,52 +105,46 @@ func (m GameModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, nil
 			}
 
-			if len([]rune(m.currentGuess)) != WordLength {
-				m.logger.Debug("Invalid guess length", "guess", m.currentGuess, "length", len([]rune(m.currentGuess)))
-				m.errorMessage = fmt.Sprintf("Word must be %d letters\n", WordLength)
-				return m, nil
-			}
+			if _, err := m.game.Guess(m.currentGuess); err != nil {
+				metrics.InvalidGuess(err)
+
+				var hardModeErr *engine.HardModeError
+
+				switch {
+				case errors.Is(err, engine.ErrWrongLength):
+					m.logger.Debug("Invalid guess length", "guess", m.currentGuess, "length", len([]rune(m.currentGuess)))
+					m.errorMessage = fmt.Sprintf("Word must be %d letters\n", engine.WordLength)
+				case errors.Is(err, engine.ErrNotInWordList):
+					m.logger.Debug("Invalid word attempted", "guess", m.currentGuess)
+					m.invalidWord = true
+					m.errorMessage = "Invalid word\n"
+				case errors.As(err, &hardModeErr):
+					m.logger.Debug("Hard mode violation", "guess", m.currentGuess, "violation", hardModeErr.Message)
+					m.invalidWord = true
+					m.errorMessage = hardModeErr.Message + "\n"
+				default:
+					m.logger.Error("Failed to apply guess", "guess", m.currentGuess, "error", err)
+					m.errorMessage = "Could not submit guess\n"
+				}
 
-			// Validate the guess against the wordlist
-			if !wordle.IsValidWord(strings.ToLower(m.currentGuess)) {
-				m.logger.Debug("Invalid word attempted", "guess", m.currentGuess)
-				m.invalidWord = true
-				m.errorMessage = "Invalid word\n"
 				return m, nil
 			}
 
-			// Process the guess
-			m.logger.Info("Valid guess submitted", "guess", m.currentGuess, "attempt", len(m.guesses)+1)
+			m.logger.Info("Valid guess submitted", "guess", m.currentGuess, "attempt", m.game.GuessCount())
 			m.errorMessage = ""
 			m.invalidWord = false
-			m.guesses = append(m.guesses, m.currentGuess)
-			result := m.evaluateGuess(m.currentGuess)
-			m.guessResults = append(m.guessResults, result)
-
-			// Update letter map
-			for _, gr := range result {
-				if len(gr.Letter) == 0 {
-					continue // Skip empty letters
-				}
-
-				letter := rune(strings.ToLower(gr.Letter)[0])
-
-				// Only update if it's better information than we had
-				if existing, ok := m.letterMap[letter]; !ok || gr.State < existing {
-					m.letterMap[letter] = gr.State
-				}
-			}
+			m.currentGuess = ""
 
 			// Check win condition
-			if strings.ToLower(m.currentGuess) == m.targetWord {
-				m.logger.Info("Game won", "attempts", len(m.guesses), "targetWord", m.targetWord)
+			switch m.game.State() {
+			case engine.StateWon:
+				m.logger.Info("Game won", "attempts", m.game.GuessCount(), "targetWord", m.game.Solution())
 				m.state = GameStateWon
-			} else if len(m.guesses) >= MaxGuesses {
-				m.logger.Info("Game lost", "attempts", len(m.guesses), "targetWord", m.targetWord)
+			case engine.StateLost:
+				m.logger.Info("Game lost", "attempts", m.game.GuessCount(), "targetWord", m.game.Solution())
 				m.state = GameStateLost
 			}
 
-			m.currentGuess = ""
 			return m, nil
 
 		case "backspace":
@@ -150,12 +157,12 @@ func (m GameModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		default:
 			// Only accept letters
 			if len(msg.String()) == 1 && msg.String()[0] >= 'a' && msg.String()[0] <= 'z' {
-				if len([]rune(m.currentGuess)) < WordLength {
+				if len([]rune(m.currentGuess)) < engine.WordLength {
 					m.currentGuess += strings.ToUpper(msg.String())
 					m.errorMessage = ""
 				}
 			} else if len(msg.String()) == 1 && msg.String()[0] >= 'A' && msg.String()[0] <= 'Z' {
-				if len([]rune(m.currentGuess)) < WordLength {
+				if len([]rune(m.currentGuess)) < engine.WordLength {
 					m.currentGuess += msg.String()
 					m.errorMessage = ""
 				}
@@ -166,58 +173,18 @@ func (m GameModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m GameModel) evaluateGuess(guess string) []GuessResult {
-	guess = strings.ToLower(guess)
-	result := make([]GuessResult, WordLength)
-	targetLetters := []rune(m.targetWord)
-	guessLetters := []rune(guess)
-	used := make([]bool, WordLength)
-
-	// Safety check: ensure guess is the correct length
-	if len(guessLetters) != WordLength || len(targetLetters) != WordLength {
-		// Return empty results if lengths don't match
-		for i := 0; i < WordLength; i++ {
-			result[i] = GuessResult{
-				Letter: "",
-				State:  LetterStateAbsent,
-			}
-		}
-
-		return result
-	}
-
-	// Initialize all results with letters (will be updated with correct states)
-	for i := 0; i < WordLength; i++ {
-		result[i] = GuessResult{
-			Letter: strings.ToUpper(string(guessLetters[i])),
-			State:  LetterStateAbsent, // Default to absent, will be updated if correct or present
-		}
-	}
-
-	// First pass: mark correct positions
-	for i := 0; i < WordLength; i++ {
-		if guessLetters[i] == targetLetters[i] {
-			result[i].State = LetterStateCorrect
-			used[i] = true
-		}
-	}
-
-	// Second pass: mark present letters
-	for i := 0; i < WordLength; i++ {
-		if result[i].State == LetterStateCorrect {
-			continue
-		}
-
-		for j := 0; j < WordLength; j++ {
-			if !used[j] && guessLetters[i] == targetLetters[j] {
-				result[i].State = LetterStatePresent
-				used[j] = true
-				break
-			}
-		}
+// tileStyle returns the board tile style for a letter state
+func tileStyle(state engine.LetterState) lipgloss.Style {
+	switch state {
+	case engine.LetterCorrect:
+		return styles.TileStyleCorrect
+	case engine.LetterPresent:
+		return styles.TileStylePresent
+	case engine.LetterAbsent:
+		return styles.TileStyleAbsent
+	default:
+		return styles.TileStyleEmpty
 	}
-
-	return result
 }
 
 func (m GameModel) renderKeyboard() string {
@@ -232,13 +199,13 @@ func (m GameModel) renderKeyboard() string {
 		var keys []string
 		for _, letter := range row {
 			var style lipgloss.Style
-			if state, exists := m.letterMap[rune(strings.ToLower(string(letter))[0])]; exists {
+			if state, exists := m.game.LetterState(letter); exists {
 				switch state {
-				case LetterStateCorrect:
+				case engine.LetterCorrect:
 					style = styles.KeyStyleCorrect
-				case LetterStatePresent:
+				case engine.LetterPresent:
 					style = styles.KeyStylePresent
-				case LetterStateAbsent:
+				case engine.LetterAbsent:
 					style = styles.KeyStyleAbsent
 				default:
 					style = styles.KeyStyleUnused
@@ -258,31 +225,20 @@ func (m GameModel) View() string {
 	var s strings.Builder
 
 	// Render previous guesses
+	feedback := m.game.Feedback()
+
 	var boardLines []string
-	for i := 0; i < MaxGuesses; i++ {
+	for i := 0; i < engine.MaxGuesses; i++ {
 		var tiles []string
 
-		if i < len(m.guessResults) {
+		if i < len(feedback) {
 			// Render completed guess with colored boxes
-			for _, result := range m.guessResults[i] {
-				var style lipgloss.Style
-
-				switch result.State {
-				case LetterStateCorrect:
-					style = styles.TileStyleCorrect
-				case LetterStatePresent:
-					style = styles.TileStylePresent
-				case LetterStateAbsent:
-					style = styles.TileStyleAbsent
-				default:
-					style = styles.TileStyleEmpty
-				}
-
-				tiles = append(tiles, style.Render(result.Letter))
+			for _, result := range feedback[i] {
+				tiles = append(tiles, tileStyle(result.State).Render(strings.ToUpper(string(result.Letter))))
 			}
-		} else if i == len(m.guesses) {
+		} else if i == len(feedback) {
 			// Render current guess being typed
-			for j := 0; j < WordLength; j++ {
+			for j := 0; j < engine.WordLength; j++ {
 				if j < len([]rune(m.currentGuess)) {
 					// Use red style if word is invalid
 					style := styles.TileStyleEmpty
@@ -297,7 +253,7 @@ func (m GameModel) View() string {
 			}
 		} else {
 			// Render empty row
-			for j := 0; j < WordLength; j++ {
+			for j := 0; j < engine.WordLength; j++ {
 				tiles = append(tiles, styles.TileStyleEmpty.Render(" "))
 			}
 		}
@@ -318,20 +274,27 @@ func (m GameModel) View() string {
 	// Show game state messages
 	switch m.state {
 	case GameStateWon:
-		s.WriteString(styles.SuccessStyle.Render(fmt.Sprintf("Congratulations! You won in %d guesses!", len(m.guesses))))
+		s.WriteString(styles.SuccessStyle.Render(fmt.Sprintf("Congratulations! You won in %d guesses!", m.game.GuessCount())))
 		s.WriteString("\n\n")
-		s.WriteString(styles.HelpStyle.Render("Enter/Esc to menu | Ctrl+C to quit"))
+		s.WriteString(renderCopied(m.copied, m.GetShareText()))
+		s.WriteString(styles.HelpStyle.Render("C to copy result | Enter/Esc to menu | Ctrl+C to quit"))
 	case GameStateLost:
 		s.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Game Over!")))
 		s.WriteString("\n\n")
-		s.WriteString(styles.HelpStyle.Render("Enter/Esc to menu | Ctrl+C to quit"))
+		s.WriteString(renderCopied(m.copied, m.GetShareText()))
+		s.WriteString(styles.HelpStyle.Render("C to copy result | Enter/Esc to menu | Ctrl+C to quit"))
 	case GameStatePlaying:
 		if m.errorMessage != "" {
 			s.WriteString(styles.ErrorStyle.Render(m.errorMessage))
 			s.WriteString("\n")
 		}
 
-		s.WriteString(styles.HelpStyle.Render(fmt.Sprintf("Guess %d/%d", len(m.guesses)+1, MaxGuesses)))
+		guessInfo := fmt.Sprintf("Guess %d/%d", m.game.GuessCount()+1, engine.MaxGuesses)
+		if m.game.HardMode() {
+			guessInfo += " | Hard mode"
+		}
+
+		s.WriteString(styles.HelpStyle.Render(guessInfo))
 		s.WriteString("\n\n")
 		s.WriteString(styles.HelpStyle.Render("Enter to submit | Backspace to delete | Esc to menu | Ctrl+C to quit"))
 	default:
@@ -348,44 +311,35 @@ func (m GameModel) GetState() GameState {
 
 // GetGameResultJSON returns the game result as a JSON string for storage
 func (m GameModel) GetGameResultJSON() string {
-	type GameResultData struct {
-		W bool     `json:"w"`           // Won
-		G []string `json:"g,omitempty"` // Guesses as compact strings: "LetterState" (c=correct, p=present, a=absent)
-	}
-
-	result := GameResultData{
-		W: m.state == GameStateWon,
-		G: []string{},
+	result, err := m.game.Result().JSON()
+	if err != nil {
+		m.logger.Error("Failed to marshal game result", "error", err)
+		return ""
 	}
 
-	// Convert guess results to compact format: each guess is "L1S1L2S2L3S3L4S4L5S5"
-	for _, guessResult := range m.guessResults {
-		var guess strings.Builder
-		for _, gr := range guessResult {
-			guess.WriteString(gr.Letter)
-			switch gr.State {
-			case LetterStateCorrect:
-				guess.WriteString("c")
-			case LetterStatePresent:
-				guess.WriteString("p")
-			case LetterStateAbsent:
-				guess.WriteString("a")
-			}
-		}
+	return result
+}
 
-		result.G = append(result.G, guess.String())
+// GetShareText returns the result in the standard shareable format
+func (m GameModel) GetShareText() string {
+	if m.practice {
+		return m.game.Result().PracticeShareText(m.asciiShare)
 	}
 
-	jsonBytes, err := json.Marshal(result)
-	if err != nil {
-		m.logger.Error("Failed to marshal game result", "error", err)
-		return ""
-	}
+	return m.game.Result().ShareText(m.puzzleNumber, m.asciiShare)
+}
+
+// GetGame returns the underlying game
+func (m GameModel) GetGame() *engine.Game {
+	return m.game
+}
 
-	return string(jsonBytes)
+// IsHardMode returns whether the game is played in hard mode
+func (m GameModel) IsHardMode() bool {
+	return m.game.HardMode()
 }
 
 // GetGuessCount returns the number of guesses made
 func (m GameModel) GetGuessCount() int {
-	return len(m.guesses)
+	return m.game.GuessCount()
 }
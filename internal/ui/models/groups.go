@@ -0,0 +1,498 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/f-gillmann/wordle-ssh/internal/engine"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+	"github.com/f-gillmann/wordle-ssh/internal/ui/styles"
+)
+
+type GroupsState int
+
+const (
+	GroupsStateBrowsing GroupsState = iota
+	GroupsStateMenu
+)
+
+// Screens of the groups view
+const (
+	groupsScreenList = iota
+	groupsScreenCreate
+	groupsScreenJoin
+	groupsScreenDetail
+)
+
+// Tabs of a group
+const (
+	groupTabToday = iota
+	groupTabSummary
+	groupTabWinRate
+	groupTabStreaks
+	groupTabMembers
+	groupTabCount
+)
+
+// GroupAction is an action on groups that the app performs for the groups view
+type GroupAction int
+
+const (
+	GroupActionList GroupAction = iota
+	GroupActionOpen
+	GroupActionCreate
+	GroupActionJoin
+	GroupActionRemoveMember
+	GroupActionRotateInvite
+	GroupActionLeave
+)
+
+// GroupRequestMsg asks the app to perform a group action, the app answers
+// with a GroupsLoadedMsg, GroupLoadedMsg or GroupErrorMsg
+type GroupRequestMsg struct {
+	Action  GroupAction
+	GroupID int64
+	Input   string            // The name of a new group or an invite code
+	Member  stats.GroupMember // The member to remove
+}
+
+// GroupsLoadedMsg carries the groups of the user and shows their list
+type GroupsLoadedMsg struct {
+	Groups []stats.Group
+	Err    error
+}
+
+// GroupLoadedMsg carries a group with its members, games and leaderboards
+// and shows its details
+type GroupLoadedMsg struct {
+	Group        *stats.Group
+	Members      []stats.GroupMember
+	Games        []stats.Game
+	Leaderboards LeaderboardData
+	Notice       string
+}
+
+// GroupErrorMsg tells the groups view that an action failed
+type GroupErrorMsg struct {
+	Err error
+}
+
+// GroupsModel lists the user's groups and shows a group's leaderboards and
+// members, the app loads and changes the groups
+type GroupsModel struct {
+	username          string
+	sshKeyFingerprint string
+	today             string
+
+	screen  int
+	state   GroupsState
+	cursor  int
+	input   string
+	err     error
+	notice  string
+	loading bool // A request is pending, keys are ignored until it is answered
+
+	groups  []stats.Group
+	group   *stats.Group
+	members []stats.GroupMember
+	games   []stats.Game
+	board   LeaderboardModel
+	tab     int
+	confirm bool // Leaving needs a second key press
+}
+
+func NewGroupsModel(username string, sshKeyFingerprint string, today string) GroupsModel {
+	return GroupsModel{
+		username:          username,
+		sshKeyFingerprint: sshKeyFingerprint,
+		today:             today,
+		screen:            groupsScreenList,
+		state:             GroupsStateBrowsing,
+		loading:           true,
+	}
+}
+
+func (m GroupsModel) Init() tea.Cmd {
+	return requestGroups(GroupRequestMsg{Action: GroupActionList})
+}
+
+// requestGroups returns a command that asks the app to perform a group action
+func requestGroups(request GroupRequestMsg) tea.Cmd {
+	return func() tea.Msg {
+		return request
+	}
+}
+
+// request marks the view as loading and asks the app to perform a group action
+func (m GroupsModel) request(request GroupRequestMsg) (tea.Model, tea.Cmd) {
+	m.loading = true
+	return m, requestGroups(request)
+}
+
+func (m GroupsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case GroupsLoadedMsg:
+		m.loading = false
+		m.groups = msg.Groups
+		m.err = msg.Err
+		m.notice = ""
+		m.screen = groupsScreenList
+		m.cursor = min(m.cursor, len(m.groups)+1)
+		return m, nil
+
+	case GroupLoadedMsg:
+		// Opening a group starts on its first tab, a reload keeps the tab
+		if m.screen != groupsScreenDetail {
+			m.tab = groupTabToday
+			m.cursor = 0
+		}
+
+		byMax := m.board.byMax
+		m.board = NewLeaderboardModel(msg.Leaderboards, m.username)
+		m.board.byMax = byMax
+
+		m.loading = false
+		m.group = msg.Group
+		m.members = msg.Members
+		m.games = msg.Games
+		m.err = nil
+		m.notice = msg.Notice
+		m.cursor = min(m.cursor, max(len(m.members)-1, 0))
+		m.screen = groupsScreenDetail
+		return m, nil
+
+	case GroupErrorMsg:
+		m.loading = false
+		m.err = msg.Err
+		return m, nil
+	}
+
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.loading {
+		return m, nil
+	}
+
+	switch m.screen {
+	case groupsScreenList:
+		return m.updateList(keyMsg)
+	case groupsScreenCreate, groupsScreenJoin:
+		return m.updateInput(keyMsg)
+	case groupsScreenDetail:
+		return m.updateDetail(keyMsg)
+	}
+
+	return m, nil
+}
+
+func (m GroupsModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// The groups are followed by "Create" and "Join"
+	items := len(m.groups) + 2
+
+	switch msg.String() {
+	case "ctrl+c", "q", "esc":
+		m.state = GroupsStateMenu
+
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.cursor < items-1 {
+			m.cursor++
+		}
+
+	case "enter":
+		m.err = nil
+		m.notice = ""
+
+		switch {
+		case m.cursor < len(m.groups):
+			return m.request(GroupRequestMsg{Action: GroupActionOpen, GroupID: m.groups[m.cursor].ID})
+		case m.cursor == len(m.groups):
+			m.screen = groupsScreenCreate
+			m.input = ""
+		default:
+			m.screen = groupsScreenJoin
+			m.input = ""
+		}
+	}
+
+	return m, nil
+}
+
+func (m GroupsModel) updateInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+c", "esc":
+		m.screen = groupsScreenList
+		m.err = nil
+
+	case "enter":
+		action := GroupActionJoin
+		if m.screen == groupsScreenCreate {
+			action = GroupActionCreate
+		}
+
+		return m.request(GroupRequestMsg{Action: action, Input: m.input})
+
+	case "backspace":
+		if len(m.input) > 0 {
+			runes := []rune(m.input)
+			m.input = string(runes[:len(runes)-1])
+		}
+
+	default:
+		// Only accept printable characters
+		if len(msg.Runes) == 1 && len([]rune(m.input)) < stats.MaxGroupNameLength {
+			m.input += string(msg.Runes)
+		}
+	}
+
+	return m, nil
+}
+
+func (m GroupsModel) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	if key != "L" {
+		m.confirm = false
+	}
+
+	switch key {
+	case "ctrl+c", "q", "esc":
+		m.cursor = 0
+		return m.request(GroupRequestMsg{Action: GroupActionList})
+
+	case "tab", "right", "l":
+		m.tab = (m.tab + 1) % groupTabCount
+		m.cursor = 0
+
+	case "shift+tab", "left", "h":
+		m.tab = (m.tab + groupTabCount - 1) % groupTabCount
+		m.cursor = 0
+
+	case "s":
+		if m.tab == groupTabStreaks {
+			m.board.byMax = !m.board.byMax
+		}
+
+	case "up", "k":
+		if m.tab == groupTabMembers && m.cursor > 0 {
+			m.cursor--
+		}
+
+	case "down", "j":
+		if m.tab == groupTabMembers && m.cursor < len(m.members)-1 {
+			m.cursor++
+		}
+
+	case "x":
+		if m.tab != groupTabMembers || !m.isOwner() || m.cursor >= len(m.members) {
+			return m, nil
+		}
+
+		return m.request(GroupRequestMsg{Action: GroupActionRemoveMember, GroupID: m.group.ID, Member: m.members[m.cursor]})
+
+	case "r":
+		if !m.isOwner() {
+			return m, nil
+		}
+
+		return m.request(GroupRequestMsg{Action: GroupActionRotateInvite, GroupID: m.group.ID})
+
+	case "L":
+		if !m.confirm {
+			m.confirm = true
+			return m, nil
+		}
+
+		m.confirm = false
+		m.cursor = 0
+		return m.request(GroupRequestMsg{Action: GroupActionLeave, GroupID: m.group.ID})
+	}
+
+	return m, nil
+}
+
+// isOwner returns whether the user owns the open group
+func (m GroupsModel) isOwner() bool {
+	return m.group != nil && m.group.IsOwner(m.username, m.sshKeyFingerprint)
+}
+
+func (m GroupsModel) View() string {
+	var s string
+
+	switch m.screen {
+	case groupsScreenList:
+		s = m.viewList()
+	case groupsScreenCreate:
+		s = m.viewInput("Create Group", "Enter a name for your group.")
+	case groupsScreenJoin:
+		s = m.viewInput("Join Group", "Enter the invite code you were given.")
+	case groupsScreenDetail:
+		s = m.viewDetail()
+	}
+
+	return s
+}
+
+func (m GroupsModel) viewList() string {
+	s := styles.MenuTitleStyle.Render("Groups")
+	s += "\n\n"
+
+	if len(m.groups) == 0 && !m.loading {
+		s += styles.HelpStyle.Render("You are not in any group yet. Create one or join with an invite code.")
+		s += "\n\n"
+	}
+
+	items := make([]string, 0, len(m.groups)+2)
+	for _, group := range m.groups {
+		item := fmt.Sprintf("%s (%d members)", group.Name, group.MemberCount)
+		if group.IsOwner(m.username, m.sshKeyFingerprint) {
+			item += " ★"
+		}
+
+		items = append(items, item)
+	}
+
+	items = append(items, "+ Create a group", "+ Join a group")
+
+	for i, item := range items {
+		if i == m.cursor {
+			s += styles.SelectedMenuItemStyle.Render("> " + item)
+		} else {
+			s += styles.MenuItemStyle.Render("  " + item)
+		}
+
+		s += "\n"
+	}
+
+	s += "\n"
+	s += m.viewMessages()
+	s += styles.HelpStyle.Render("↑/↓/j/k to navigate | Enter to select | Esc to return to menu")
+	return s
+}
+
+func (m GroupsModel) viewInput(title string, prompt string) string {
+	s := styles.MenuTitleStyle.Render(title)
+	s += "\n\n"
+	s += "  " + prompt + "\n"
+	s += fmt.Sprintf("  > %s█\n\n", m.input)
+	s += m.viewMessages()
+	s += styles.HelpStyle.Render("Enter to confirm | Esc to cancel")
+	return s
+}
+
+func (m GroupsModel) viewDetail() string {
+	s := styles.MenuTitleStyle.Render(m.group.Name)
+	s += "\n\n"
+	s += fmt.Sprintf("  Invite code: %s    Members: %d\n", m.group.InviteCode, m.group.MemberCount)
+	s += styles.HelpStyle.Render(fmt.Sprintf("  Others can join with: ssh <host> join %s", m.group.InviteCode))
+	s += "\n\n"
+	s += renderTabBar([]string{"Today", "Summary", "Win Rate", "Streaks", "Members"}, m.tab)
+	s += "\n\n"
+
+	switch m.tab {
+	case groupTabToday:
+		s += m.board.renderToday()
+	case groupTabSummary:
+		s += m.viewSummary()
+	case groupTabWinRate:
+		s += m.board.renderWinRate()
+	case groupTabStreaks:
+		s += m.board.renderStreaks()
+	case groupTabMembers:
+		s += m.viewMembers()
+	}
+
+	s += "\n"
+	s += m.viewMessages()
+
+	help := "←/→/Tab to switch tab | Esc to go back"
+	switch {
+	case m.confirm && m.isOwner():
+		help = "Press L again to delete the group for everyone"
+	case m.confirm:
+		help = "Press L again to leave the group"
+	case m.tab == groupTabStreaks:
+		help = "←/→/Tab to switch tab | S to sort by current/max | Esc to go back"
+	case m.tab == groupTabMembers && m.isOwner():
+		help = "↑/↓ to select | X to remove member | R to rotate invite code | L to delete group | Esc to go back"
+	case m.tab == groupTabMembers:
+		help = "L to leave group | Esc to go back"
+	}
+
+	s += styles.HelpStyle.Render(help)
+	return s
+}
+
+// viewSummary renders every member's grid of today's puzzle side by side
+func (m GroupsModel) viewSummary() string {
+	s := styles.HelpStyle.Render(fmt.Sprintf("Everyone's results for %s", m.today))
+	s += "\n\n"
+
+	played := make(map[string]stats.Game, len(m.games))
+	for _, game := range m.games {
+		played[game.Username+"\x00"+game.SSHKeyFingerprint] = game
+	}
+
+	card := lipgloss.NewStyle().Width(16).MarginRight(2)
+
+	var cards []string
+	for _, member := range m.members {
+		body := styles.HelpStyle.Render("not played yet")
+		if game, ok := played[member.Username+"\x00"+member.SSHKeyFingerprint]; ok {
+			body = "?"
+			if result, err := engine.ParseResult(game.GameResult); err == nil {
+				body = result.Score() + "\n" + result.Grid()
+			}
+		}
+
+		cards = append(cards, card.Render(truncate(member.Username, 15)+"\n"+body))
+	}
+
+	// Wrap the cards into rows of four
+	var rows []string
+	for i := 0; i < len(cards); i += 4 {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, cards[i:min(i+4, len(cards))]...))
+	}
+
+	return s + lipgloss.NewStyle().PaddingLeft(2).Render(strings.Join(rows, "\n\n")) + "\n"
+}
+
+func (m GroupsModel) viewMembers() string {
+	var s string
+	for i, member := range m.members {
+		line := fmt.Sprintf("%-20s joined %s", truncate(member.Username, 20), member.JoinedAt.Format(stats.DateFormat))
+		if m.group.IsOwner(member.Username, member.SSHKeyFingerprint) {
+			line += "  owner"
+		}
+
+		if i == m.cursor {
+			s += styles.SelectedMenuItemStyle.Render("> " + line)
+		} else {
+			s += styles.MenuItemStyle.Render("  " + line)
+		}
+
+		s += "\n"
+	}
+
+	return s
+}
+
+// viewMessages renders the current notice or error
+func (m GroupsModel) viewMessages() string {
+	switch {
+	case m.err != nil:
+		return styles.ErrorStyle.Render(fmt.Sprintf("✗ %s", m.err.Error())) + "\n\n"
+	case m.notice != "":
+		return styles.SuccessStyle.Render(m.notice) + "\n\n"
+	default:
+		return ""
+	}
+}
+
+func (m GroupsModel) GetState() GroupsState {
+	return m.state
+}
@@ -0,0 +1,231 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f-gillmann/wordle-ssh/internal/stats"
+	"github.com/f-gillmann/wordle-ssh/internal/ui/styles"
+)
+
+type LeaderboardState int
+
+const (
+	LeaderboardStateViewing LeaderboardState = iota
+	LeaderboardStateMenu
+)
+
+// Leaderboard tabs
+const (
+	leaderboardTabToday = iota
+	leaderboardTabWinRate
+	leaderboardTabStreaks
+	leaderboardTabCount
+)
+
+// LeaderboardData holds the entries of every leaderboard tab
+type LeaderboardData struct {
+	Date           string
+	Today          []stats.LeaderboardEntry
+	WinRate        []stats.LeaderboardEntry
+	CurrentStreaks []stats.LeaderboardEntry
+	MaxStreaks     []stats.LeaderboardEntry
+	MinGames       int
+	Visible        bool // Whether the viewing user appears on leaderboards
+}
+
+type LeaderboardModel struct {
+	data     LeaderboardData
+	username string
+	tab      int
+	byMax    bool
+	state    LeaderboardState
+}
+
+func NewLeaderboardModel(data LeaderboardData, username string) LeaderboardModel {
+	return LeaderboardModel{
+		data:     data,
+		username: username,
+		tab:      leaderboardTabToday,
+		state:    LeaderboardStateViewing,
+	}
+}
+
+func (m LeaderboardModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m LeaderboardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "ctrl+c", "q", "esc", "enter":
+			m.state = LeaderboardStateMenu
+			return m, nil
+
+		case "tab", "right", "l":
+			m.tab = (m.tab + 1) % leaderboardTabCount
+
+		case "shift+tab", "left", "h":
+			m.tab = (m.tab + leaderboardTabCount - 1) % leaderboardTabCount
+
+		case "s":
+			if m.tab == leaderboardTabStreaks {
+				m.byMax = !m.byMax
+			}
+		}
+	}
+
+	return m, nil
+}
+
+func (m LeaderboardModel) View() string {
+	s := styles.MenuTitleStyle.Render("Leaderboard")
+	s += "\n\n"
+	s += m.renderTabs()
+	s += "\n\n"
+
+	switch m.tab {
+	case leaderboardTabToday:
+		s += m.renderToday()
+	case leaderboardTabWinRate:
+		s += m.renderWinRate()
+	case leaderboardTabStreaks:
+		s += m.renderStreaks()
+	}
+
+	s += "\n"
+	if !m.data.Visible {
+		s += styles.HelpStyle.Render("You are hidden from leaderboards, change this in Settings.")
+		s += "\n"
+	}
+
+	help := "←/→/Tab to switch tab | Esc to return to menu"
+	if m.tab == leaderboardTabStreaks {
+		help = "←/→/Tab to switch tab | S to sort by current/max | Esc to return to menu"
+	}
+
+	s += styles.HelpStyle.Render(help)
+	return s
+}
+
+// renderTabs renders the tab bar with the active tab highlighted
+func (m LeaderboardModel) renderTabs() string {
+	return renderTabBar([]string{"Today", "Win Rate", "Streaks"}, m.tab)
+}
+
+// renderTabBar renders tab names with the active one highlighted
+func renderTabBar(names []string, active int) string {
+	var tabs []string
+	for i, name := range names {
+		if i == active {
+			tabs = append(tabs, styles.SelectedMenuItemStyle.Render("["+name+"]"))
+		} else {
+			tabs = append(tabs, styles.MenuItemStyle.Render(" "+name+" "))
+		}
+	}
+
+	return strings.Join(tabs, " ")
+}
+
+func (m LeaderboardModel) renderToday() string {
+	s := styles.HelpStyle.Render(fmt.Sprintf("Puzzle of %s, ranked by guesses then solve time", m.data.Date))
+	s += "\n\n"
+
+	if len(m.data.Today) == 0 {
+		return s + "  Nobody has solved today's puzzle yet.\n"
+	}
+
+	for _, entry := range m.data.Today {
+		value := fmt.Sprintf("%d/6", entry.Guesses)
+		if entry.HardMode {
+			value += "*"
+		}
+
+		if entry.SolveTime > 0 {
+			value += "  " + formatSolveTime(entry.SolveTime)
+		}
+
+		s += m.renderRow(entry, value)
+	}
+
+	return s
+}
+
+func (m LeaderboardModel) renderWinRate() string {
+	subtitle := fmt.Sprintf("Players with at least %d games", m.data.MinGames)
+	if m.data.MinGames <= 1 {
+		subtitle = "Players with at least one game"
+	}
+
+	s := styles.HelpStyle.Render(subtitle)
+	s += "\n\n"
+
+	if len(m.data.WinRate) == 0 {
+		return s + "  Nobody has played enough games yet.\n"
+	}
+
+	for _, entry := range m.data.WinRate {
+		s += m.renderRow(entry, fmt.Sprintf("%5.1f%%  %d/%d", entry.WinRate(), entry.GamesWon, entry.GamesPlayed))
+	}
+
+	return s
+}
+
+func (m LeaderboardModel) renderStreaks() string {
+	entries := m.data.CurrentStreaks
+	title := "Longest current streaks"
+	if m.byMax {
+		entries = m.data.MaxStreaks
+		title = "Longest streaks of all time"
+	}
+
+	s := styles.HelpStyle.Render(title)
+	s += "\n\n"
+
+	if len(entries) == 0 {
+		return s + "  Nobody has a streak yet.\n"
+	}
+
+	for _, entry := range entries {
+		s += m.renderRow(entry, fmt.Sprintf("current %3d  max %3d", entry.CurrentStreak, entry.MaxStreak))
+	}
+
+	return s
+}
+
+// renderRow renders a ranked entry, highlighting the viewing user
+func (m LeaderboardModel) renderRow(entry stats.LeaderboardEntry, value string) string {
+	line := fmt.Sprintf("%3d. %-20s %s", entry.Rank, truncate(entry.Username, 20), value)
+	if entry.Username == m.username {
+		return styles.SelectedMenuItemStyle.Render(">"+line) + "\n"
+	}
+
+	return styles.MenuItemStyle.Render(line) + "\n"
+}
+
+// formatSolveTime formats a duration as m:ss or h:mm:ss
+func formatSolveTime(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d >= time.Hour {
+		return fmt.Sprintf("%d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+	}
+
+	return fmt.Sprintf("%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+}
+
+// truncate shortens a string to at most n runes
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+
+	return string(runes[:n-1]) + "…"
+}
+
+func (m LeaderboardModel) GetState() LeaderboardState {
+	return m.state
+}
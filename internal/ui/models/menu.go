@@ -12,7 +12,11 @@ type MenuState int
 const (
 	MenuStateMain MenuState = iota
 	MenuStateGame
+	MenuStateArchive
 	MenuStateStats
+	MenuStateLeaderboard
+	MenuStateGroups
+	MenuStateSettings
 	MenuStateDeleteData
 	MenuStateExit
 )
@@ -28,13 +32,18 @@ type MenuModel struct {
 	selected    int
 	state       MenuState
 	hasUserData bool
+	guest       bool
 	motd        string
 }
 
 func NewMenuModel(hasUserData bool, motd string) MenuModel {
 	choices := []MenuItem{
 		{Title: "Play Wordle", Description: "Start a new game"},
+		{Title: "Archive", Description: "Play a past puzzle"},
 		{Title: "View Stats", Description: "View your statistics"},
+		{Title: "Leaderboard", Description: "See how you rank against other players"},
+		{Title: "Groups", Description: "Compete with your team in a private group"},
+		{Title: "Settings", Description: "Change your timezone, hard mode and leaderboard visibility"},
 	}
 
 	// Only add "Delete My Data" option if user has data
@@ -54,6 +63,21 @@ func NewMenuModel(hasUserData bool, motd string) MenuModel {
 	}
 }
 
+// NewGuestMenuModel creates the menu of a guest without an SSH key, who can only practice
+func NewGuestMenuModel(motd string) MenuModel {
+	return MenuModel{
+		choices: []MenuItem{
+			{Title: "Practice", Description: "Play a random past puzzle, nothing is recorded"},
+			{Title: "Exit", Description: "Quit the application"},
+		},
+		cursor:   0,
+		selected: -1,
+		state:    MenuStateMain,
+		guest:    true,
+		motd:     motd,
+	}
+}
+
 func (m MenuModel) Init() tea.Cmd {
 	return nil
 }
@@ -82,10 +106,18 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Determine action based on menu choice
 			selectedTitle := m.choices[m.cursor].Title
 			switch selectedTitle {
-			case "Play Wordle":
+			case "Play Wordle", "Practice":
 				m.state = MenuStateGame
+			case "Archive":
+				m.state = MenuStateArchive
 			case "View Stats":
 				m.state = MenuStateStats
+			case "Leaderboard":
+				m.state = MenuStateLeaderboard
+			case "Groups":
+				m.state = MenuStateGroups
+			case "Settings":
+				m.state = MenuStateSettings
 			case "Delete My Data":
 				m.state = MenuStateDeleteData
 			case "Exit":
@@ -104,6 +136,11 @@ func (m MenuModel) View() string {
 	s := styles.MenuTitleStyle.Render(m.motd)
 	s += "\n\n"
 
+	if m.guest {
+		s += styles.HelpStyle.Render("Playing as a guest, connect with an SSH key to play the daily puzzle and keep your stats.")
+		s += "\n\n"
+	}
+
 	for i, choice := range m.choices {
 		cursor := " "
 		if m.cursor == i {
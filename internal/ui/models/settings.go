@@ -0,0 +1,247 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f-gillmann/wordle-ssh/internal/ui/styles"
+)
+
+type SettingsState int
+
+const (
+	SettingsStateEditing SettingsState = iota
+	SettingsStateSaved
+	SettingsStateMenu
+)
+
+// Settings fields that can be focused
+const (
+	settingsFieldTimezone = iota
+	settingsFieldHardMode
+	settingsFieldLeaderboard
+	settingsFieldASCIIShare
+	settingsFieldCount
+)
+
+type SettingsModel struct {
+	timezone    string
+	hardMode    bool
+	leaderboard bool
+	asciiShare  bool
+	input       string
+	focus       int
+	state       SettingsState
+	err         error
+}
+
+func NewSettingsModel(timezone string, hardMode bool, leaderboard bool, asciiShare bool) SettingsModel {
+	return SettingsModel{
+		timezone:    timezone,
+		hardMode:    hardMode,
+		leaderboard: leaderboard,
+		asciiShare:  asciiShare,
+		input:       timezone,
+		focus:       settingsFieldTimezone,
+		state:       SettingsStateEditing,
+	}
+}
+
+func (m SettingsModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m SettingsModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch m.state {
+	case SettingsStateEditing:
+		switch msg := msg.(type) {
+		case tea.KeyMsg:
+			switch msg.String() {
+			case "ctrl+c", "esc":
+				m.state = SettingsStateMenu
+				return m, nil
+
+			case "tab", "down":
+				m.focus = (m.focus + 1) % settingsFieldCount
+				return m, nil
+
+			case "shift+tab", "up":
+				m.focus = (m.focus + settingsFieldCount - 1) % settingsFieldCount
+				return m, nil
+
+			case " ", "left", "right":
+				switch m.focus {
+				case settingsFieldHardMode:
+					m.hardMode = !m.hardMode
+				case settingsFieldLeaderboard:
+					m.leaderboard = !m.leaderboard
+				case settingsFieldASCIIShare:
+					m.asciiShare = !m.asciiShare
+				}
+				return m, nil
+
+			case "enter":
+				// An empty timezone resets to the server's timezone
+				timezone := strings.TrimSpace(m.input)
+				if timezone != "" {
+					if _, err := time.LoadLocation(timezone); err != nil {
+						m.err = fmt.Errorf("unknown timezone %q", timezone)
+						return m, nil
+					}
+				}
+
+				m.timezone = timezone
+				m.err = nil
+				m.state = SettingsStateSaved
+				return m, nil
+
+			case "backspace":
+				if m.focus == settingsFieldTimezone && len(m.input) > 0 {
+					m.input = m.input[:len(m.input)-1]
+				}
+
+			default:
+				// Only accept printable characters
+				if m.focus == settingsFieldTimezone && len(msg.String()) == 1 {
+					m.input += msg.String()
+				}
+			}
+		}
+
+	case SettingsStateSaved:
+		switch msg.(type) {
+		case tea.KeyMsg:
+			m.state = SettingsStateMenu
+			return m, nil
+		}
+	}
+
+	return m, nil
+}
+
+func (m SettingsModel) View() string {
+	switch m.state {
+	case SettingsStateEditing:
+		s := styles.MenuTitleStyle.Render("Settings")
+		s += "\n\n"
+		s += fmt.Sprintf("  Current timezone:  %s\n", m.timezoneName())
+		s += fmt.Sprintf("  Local time:        %s\n", m.localTime())
+		s += "\n"
+
+		s += m.renderLabel(settingsFieldTimezone, "Timezone")
+		s += "\n"
+		s += "  Enter an IANA timezone (e.g. Europe/Berlin, America/New_York, Asia/Tokyo).\n"
+		s += "  Leave empty to use the server's timezone.\n"
+		if m.focus == settingsFieldTimezone {
+			s += fmt.Sprintf("  > %s█\n\n", m.input)
+		} else {
+			s += fmt.Sprintf("  > %s\n\n", m.input)
+		}
+
+		s += m.renderLabel(settingsFieldHardMode, "Hard Mode")
+		s += "\n"
+		s += "  Any revealed hints must be used in subsequent guesses.\n"
+		if m.hardMode {
+			s += "  [x] enabled\n\n"
+		} else {
+			s += "  [ ] disabled\n\n"
+		}
+
+		s += m.renderLabel(settingsFieldLeaderboard, "Leaderboard")
+		s += "\n"
+		s += "  Show your username and results on the leaderboards.\n"
+		if m.leaderboard {
+			s += "  [x] visible\n\n"
+		} else {
+			s += "  [ ] hidden\n\n"
+		}
+
+		s += m.renderLabel(settingsFieldASCIIShare, "Share Format")
+		s += "\n"
+		s += "  Copy results with plain characters for terminals that can't show emoji.\n"
+		if m.asciiShare {
+			s += "  [x] ASCII (G Y .)\n\n"
+		} else {
+			s += "  [ ] emoji (🟩🟨⬛)\n\n"
+		}
+
+		if m.err != nil {
+			s += styles.ErrorStyle.Render(fmt.Sprintf("✗ %s", m.err.Error()))
+			s += "\n\n"
+		}
+
+		s += styles.HelpStyle.Render("Tab/↑/↓ to switch field | Space to toggle | Enter to save | Esc to cancel")
+		return s
+
+	case SettingsStateSaved:
+		s := styles.MenuTitleStyle.Render("✓ Settings Saved")
+		s += "\n\n"
+		s += styles.SuccessStyle.Render(fmt.Sprintf("Your puzzle day now follows %s.", m.timezoneName()))
+		if m.hardMode {
+			s += "\n"
+			s += styles.SuccessStyle.Render("Hard mode is enabled for new games.")
+		}
+		s += "\n\n"
+		s += styles.HelpStyle.Render("Press any key to return to menu...")
+		return s
+
+	default:
+		return ""
+	}
+}
+
+// renderLabel renders a field label, highlighted when it has focus
+func (m SettingsModel) renderLabel(field int, label string) string {
+	if m.focus == field {
+		return styles.SelectedMenuItemStyle.Render("> " + label)
+	}
+
+	return styles.MenuItemStyle.Render("  " + label)
+}
+
+// timezoneName returns the display name of the selected timezone
+func (m SettingsModel) timezoneName() string {
+	if m.timezone == "" {
+		return "server default"
+	}
+
+	return m.timezone
+}
+
+// localTime returns the current time in the selected timezone
+func (m SettingsModel) localTime() string {
+	loc := time.Local
+	if m.timezone != "" {
+		if l, err := time.LoadLocation(m.timezone); err == nil {
+			loc = l
+		}
+	}
+
+	return time.Now().In(loc).Format("2006-01-02 15:04 MST")
+}
+
+func (m SettingsModel) GetState() SettingsState {
+	return m.state
+}
+
+// GetHardMode returns whether hard mode is enabled
+func (m SettingsModel) GetHardMode() bool {
+	return m.hardMode
+}
+
+// GetLeaderboard returns whether the user appears on leaderboards
+func (m SettingsModel) GetLeaderboard() bool {
+	return m.leaderboard
+}
+
+// GetASCIIShare returns whether results are shared without emoji
+func (m SettingsModel) GetASCIIShare() bool {
+	return m.asciiShare
+}
+
+// GetTimezone returns the selected timezone, empty for the server's timezone
+func (m SettingsModel) GetTimezone() string {
+	return m.timezone
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/f-gillmann/wordle-ssh/internal/ui/styles"
+)
+
+// CopyMsg asks the app to copy text to the client's clipboard
+type CopyMsg struct {
+	Text string
+}
+
+// copyToClipboard returns a command that copies text to the client's clipboard
+func copyToClipboard(text string) tea.Cmd {
+	return func() tea.Msg {
+		return CopyMsg{Text: text}
+	}
+}
+
+// renderCopied renders the copied result, so it can still be selected by hand
+// in terminals without OSC52 clipboard support
+func renderCopied(copied bool, shareText string) string {
+	if !copied {
+		return ""
+	}
+
+	s := styles.SuccessStyle.Render("✓ Copied result to clipboard")
+	s += "\n\n"
+	s += shareText
+	s += "\n\n"
+	return s
+}
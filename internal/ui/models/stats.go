@@ -84,6 +84,32 @@ func (m StatsModel) View() string {
 		s.WriteString("\n")
 	}
 
+	// Hard mode records are compared separately
+	if m.stats.HardModeGamesPlayed > 0 {
+		s.WriteString("\n")
+		s.WriteString(titleStyle.Render("Hard Mode"))
+		s.WriteString("\n\n")
+
+		hardModeList := []struct {
+			label string
+			value string
+		}{
+			{"Games Played", fmt.Sprintf("%d", m.stats.HardModeGamesPlayed)},
+			{"Games Won", fmt.Sprintf("%d", m.stats.HardModeGamesWon)},
+			{"Win Rate", fmt.Sprintf("%.1f%%", m.stats.GetHardModeWinRate())},
+			{"Average Guesses", fmt.Sprintf("%.2f", m.stats.GetHardModeAverageGuesses())},
+		}
+
+		for _, stat := range hardModeList {
+			line := statStyle.Render(
+				labelStyle.Render(stat.label+": ") +
+					valueStyle.Render(stat.value),
+			)
+			s.WriteString(line)
+			s.WriteString("\n")
+		}
+	}
+
 	// Guess distribution
 	s.WriteString("\n")
 	s.WriteString(titleStyle.Render("Guess Distribution"))
@@ -115,4 +115,22 @@ var (
 			Padding(0, 1).
 			Bold(true).
 			Align(lipgloss.Center)
+
+	CalendarWonStyle = lipgloss.NewStyle().
+				Foreground(colorGreen).
+				Bold(true)
+
+	CalendarLostStyle = lipgloss.NewStyle().
+				Foreground(colorRed)
+
+	CalendarOpenStyle = lipgloss.NewStyle().
+				Foreground(colorLightGray)
+
+	CalendarDisabledStyle = lipgloss.NewStyle().
+				Foreground(colorDarkGray)
+
+	CalendarSelectedStyle = lipgloss.NewStyle().
+				Foreground(colorWhite).
+				Background(colorPurple).
+				Bold(true)
 )
@@ -0,0 +1,99 @@
+package wordle
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// ScheduleSource serves solutions from a local schedule file.
+//
+// Each non-empty line holds a date and its solution separated by whitespace
+// or a comma, e.g. "2025-01-31 crane". Lines starting with # are ignored,
+// every date may only be scheduled once.
+type ScheduleSource struct {
+	path      string
+	solutions map[string]string
+}
+
+// NewScheduleSource loads a schedule file
+func NewScheduleSource(path string) (*ScheduleSource, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open schedule file: %w", err)
+	}
+
+	defer func() {
+		_ = file.Close()
+	}()
+
+	solutions := make(map[string]string)
+	lines := make(map[string]int)
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(strings.ReplaceAll(line, ",", " "))
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("%s:%d: expected \"<date> <solution>\"", path, lineNumber)
+		}
+
+		if _, err := time.Parse("2006-01-02", fields[0]); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid date %q", path, lineNumber, fields[0])
+		}
+
+		puzzle, err := newPuzzle(fields[0], fields[1], "schedule")
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+
+		if previous, ok := lines[puzzle.Date]; ok {
+			return nil, fmt.Errorf("%s:%d: date %s is already scheduled on line %d", path, lineNumber, puzzle.Date, previous)
+		}
+
+		solutions[puzzle.Date] = puzzle.Solution
+		lines[puzzle.Date] = lineNumber
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read schedule file: %w", err)
+	}
+
+	return &ScheduleSource{
+		path:      path,
+		solutions: solutions,
+	}, nil
+}
+
+// Name returns the name of the source
+func (s *ScheduleSource) Name() string {
+	return "schedule"
+}
+
+// FetchPuzzle returns the scheduled puzzle for a given date
+func (s *ScheduleSource) FetchPuzzle(date string) (*Puzzle, error) {
+	solution, ok := s.solutions[date]
+	if !ok {
+		return nil, fmt.Errorf("%w %s in %s", ErrPuzzleNotFound, date, s.path)
+	}
+
+	return &Puzzle{
+		Date:     date,
+		Solution: solution,
+		Source:   s.Name(),
+		Number:   PuzzleNumber(date),
+	}, nil
+}
+
+// Len returns the number of scheduled puzzles
+func (s *ScheduleSource) Len() int {
+	return len(s.solutions)
+}
@@ -0,0 +1,94 @@
+package wordle
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeSchedule writes a schedule file and returns its path
+func writeSchedule(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "schedule.txt")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("failed to write schedule: %v", err)
+	}
+
+	return path
+}
+
+func TestNewScheduleSource(t *testing.T) {
+	path := writeSchedule(t, `# Words for the office server
+
+2025-01-31 crane
+2025-02-01,SLATE
+  # indented comment
+2025-02-02 ,  trace
+`)
+
+	schedule, err := NewScheduleSource(path)
+	if err != nil {
+		t.Fatalf("NewScheduleSource() error = %v", err)
+	}
+
+	if schedule.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", schedule.Len())
+	}
+
+	want := map[string]string{"2025-01-31": "crane", "2025-02-01": "slate", "2025-02-02": "trace"}
+	for date, solution := range want {
+		puzzle, err := schedule.FetchPuzzle(date)
+		if err != nil {
+			t.Errorf("FetchPuzzle(%q) error = %v", date, err)
+			continue
+		}
+
+		if puzzle.Solution != solution || puzzle.Source != "schedule" || puzzle.Number != PuzzleNumber(date) {
+			t.Errorf("FetchPuzzle(%q) = %q from %s #%d, want %q from schedule #%d",
+				date, puzzle.Solution, puzzle.Source, puzzle.Number, solution, PuzzleNumber(date))
+		}
+	}
+
+	if _, err := schedule.FetchPuzzle("2025-02-03"); !errors.Is(err, ErrPuzzleNotFound) {
+		t.Errorf("FetchPuzzle() of an unscheduled date error = %v, want %v", err, ErrPuzzleNotFound)
+	}
+}
+
+func TestNewScheduleSourceRejectsInvalidLines(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		want    string
+	}{
+		{"missing solution", "2025-01-31\n", `:1: expected "<date> <solution>"`},
+		{"extra field", "2025-01-31 crane slate\n", `:1: expected "<date> <solution>"`},
+		{"bad date", "# header\n2025-02-30 crane\n", `:2: invalid date "2025-02-30"`},
+		{"date in another format", "31.01.2025 crane\n", `:1: invalid date "31.01.2025"`},
+		{"short word", "2025-01-31 cran\n", `:1: invalid solution "cran"`},
+		{"long word", "2025-01-31 cranes\n", `:1: invalid solution "cranes"`},
+		{"not letters", "2025-01-31 cr4ne\n", `:1: invalid solution "cr4ne"`},
+		{"duplicate date", "2025-01-31 crane\n2025-02-01 slate\n2025-01-31 trace\n", ":3: date 2025-01-31 is already scheduled on line 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewScheduleSource(writeSchedule(t, tt.content))
+			if err == nil {
+				t.Fatal("NewScheduleSource() returned no error")
+			}
+
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("NewScheduleSource() error = %q, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewScheduleSourceMissingFile(t *testing.T) {
+	if _, err := NewScheduleSource(filepath.Join(t.TempDir(), "missing.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("NewScheduleSource() error = %v, want %v", err, os.ErrNotExist)
+	}
+}
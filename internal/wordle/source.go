@@ -0,0 +1,106 @@
+package wordle
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FirstPuzzleDate is the date of the first Wordle puzzle
+const FirstPuzzleDate = "2021-06-19"
+
+// ErrPuzzleNotFound is returned when a source has no puzzle for the requested date
+var ErrPuzzleNotFound = errors.New("no puzzle for date")
+
+// Puzzle is the Wordle solution for a single date
+type Puzzle struct {
+	Date     string // YYYY-MM-DD
+	Solution string
+	Source   string // Name of the source that provided the solution
+	Number   int    // Puzzle number as shown when sharing, e.g. "Wordle 1,234"
+}
+
+// PuzzleNumber returns the number of the puzzle for a date, counted in days since the first puzzle
+func PuzzleNumber(date string) int {
+	first, err := time.Parse("2006-01-02", FirstPuzzleDate)
+	if err != nil {
+		return 0
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil || day.Before(first) {
+		return 0
+	}
+
+	return int(day.Sub(first).Hours() / 24)
+}
+
+// WordSource provides the daily Wordle solution for a date
+type WordSource interface {
+	// Name identifies the source in logs and stored puzzles
+	Name() string
+
+	// FetchPuzzle returns the puzzle for a date in YYYY-MM-DD format
+	FetchPuzzle(date string) (*Puzzle, error)
+}
+
+// newPuzzle validates and normalizes a solution before wrapping it in a Puzzle
+func newPuzzle(date string, solution string, source string) (*Puzzle, error) {
+	solution = strings.ToLower(strings.TrimSpace(solution))
+	if len(solution) != 5 {
+		return nil, fmt.Errorf("invalid solution %q for %s from %s", solution, date, source)
+	}
+
+	for _, r := range solution {
+		if r < 'a' || r > 'z' {
+			return nil, fmt.Errorf("invalid solution %q for %s from %s", solution, date, source)
+		}
+	}
+
+	return &Puzzle{
+		Date:     date,
+		Solution: solution,
+		Source:   source,
+		Number:   PuzzleNumber(date),
+	}, nil
+}
+
+// ChainSource tries each source in order and returns the first puzzle found
+type ChainSource struct {
+	sources []WordSource
+}
+
+// NewChainSource creates a source that falls back through the given sources
+func NewChainSource(sources ...WordSource) *ChainSource {
+	return &ChainSource{sources: sources}
+}
+
+// Name returns the names of all chained sources
+func (c *ChainSource) Name() string {
+	names := make([]string, 0, len(c.sources))
+	for _, source := range c.sources {
+		names = append(names, source.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+// FetchPuzzle returns the puzzle from the first source that succeeds
+func (c *ChainSource) FetchPuzzle(date string) (*Puzzle, error) {
+	if len(c.sources) == 0 {
+		return nil, fmt.Errorf("no word sources configured")
+	}
+
+	var errs []error
+	for _, source := range c.sources {
+		puzzle, err := source.FetchPuzzle(date)
+		if err == nil {
+			return puzzle, nil
+		}
+
+		errs = append(errs, fmt.Errorf("%s: %w", source.Name(), err))
+	}
+
+	return nil, errors.Join(errs...)
+}
@@ -0,0 +1,137 @@
+package wordle
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// failingSource is a word source that always fails
+type failingSource struct {
+	err error
+}
+
+func (s failingSource) Name() string {
+	return "failing"
+}
+
+func (s failingSource) FetchPuzzle(date string) (*Puzzle, error) {
+	return nil, s.err
+}
+
+func TestPuzzleNumber(t *testing.T) {
+	tests := []struct {
+		date string
+		want int
+	}{
+		{"2021-06-18", 0}, // before the first puzzle
+		{FirstPuzzleDate, 0},
+		{"2021-06-20", 1},
+		{"2022-06-19", 365},
+		{"2024-03-01", 986}, // counts the leap day of 2024 once it has passed
+		{"not a date", 0},
+	}
+
+	for _, tt := range tests {
+		if got := PuzzleNumber(tt.date); got != tt.want {
+			t.Errorf("PuzzleNumber(%q) = %d, want %d", tt.date, got, tt.want)
+		}
+	}
+}
+
+func TestNewPuzzle(t *testing.T) {
+	tests := []struct {
+		solution string
+		want     string
+		wantErr  bool
+	}{
+		{"crane", "crane", false},
+		{" CRANE\n", "crane", false},
+		{"cran", "", true},
+		{"cranes", "", true},
+		{"cr4ne", "", true},
+		{"crâne", "", true},
+		{"", "", true},
+	}
+
+	for _, tt := range tests {
+		puzzle, err := newPuzzle("2025-01-31", tt.solution, "test")
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("newPuzzle(%q) = %q, want an error", tt.solution, puzzle.Solution)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("newPuzzle(%q) error = %v", tt.solution, err)
+			continue
+		}
+
+		if puzzle.Solution != tt.want || puzzle.Number != PuzzleNumber("2025-01-31") {
+			t.Errorf("newPuzzle(%q) = %q #%d, want %q #%d", tt.solution, puzzle.Solution, puzzle.Number, tt.want, PuzzleNumber("2025-01-31"))
+		}
+	}
+}
+
+func TestChainSourceFallsBack(t *testing.T) {
+	// The NYT API is down, the schedule has the word
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	nyt := NewNYTSource(0)
+	nyt.baseURL = server.URL
+
+	path := filepath.Join(t.TempDir(), "schedule.txt")
+	if err := os.WriteFile(path, []byte("2025-01-31 crane\n"), 0o644); err != nil {
+		t.Fatalf("failed to write schedule: %v", err)
+	}
+
+	schedule, err := NewScheduleSource(path)
+	if err != nil {
+		t.Fatalf("NewScheduleSource() error = %v", err)
+	}
+
+	chain := NewChainSource(nyt, schedule)
+	if name := chain.Name(); name != "nyt,schedule" {
+		t.Errorf("Name() = %q, want %q", name, "nyt,schedule")
+	}
+
+	puzzle, err := chain.FetchPuzzle("2025-01-31")
+	if err != nil {
+		t.Fatalf("FetchPuzzle() error = %v", err)
+	}
+
+	if puzzle.Solution != "crane" || puzzle.Source != "schedule" {
+		t.Errorf("FetchPuzzle() = %q from %s, want crane from schedule", puzzle.Solution, puzzle.Source)
+	}
+
+	// Without a scheduled word the errors of every source are returned
+	_, err = chain.FetchPuzzle("2025-02-01")
+	if !errors.Is(err, ErrPuzzleNotFound) {
+		t.Errorf("FetchPuzzle() error = %v, want %v", err, ErrPuzzleNotFound)
+	}
+
+	if err != nil && !strings.Contains(err.Error(), "nyt: ") {
+		t.Errorf("FetchPuzzle() error = %q, want the error of the nyt source too", err)
+	}
+}
+
+func TestChainSourceWhenEverySourceFails(t *testing.T) {
+	sourceErr := errors.New("unavailable")
+	chain := NewChainSource(failingSource{err: sourceErr}, failingSource{err: sourceErr})
+
+	if _, err := chain.FetchPuzzle("2025-01-31"); !errors.Is(err, sourceErr) {
+		t.Errorf("FetchPuzzle() error = %v, want %v", err, sourceErr)
+	}
+
+	if _, err := NewChainSource().FetchPuzzle("2025-01-31"); err == nil {
+		t.Error("FetchPuzzle() without sources returned no error")
+	}
+}
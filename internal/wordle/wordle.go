@@ -7,6 +7,11 @@ import (
 	"time"
 )
 
+const (
+	nytBaseURL        = "https://www.nytimes.com/svc/wordle/v2"
+	defaultNYTTimeout = 10 * time.Second
+)
+
 // Response is the JSON structure from our NYTimes Wordle API response
 type Response struct {
 	Solution string `json:"solution"`
@@ -16,35 +21,60 @@ type Response struct {
 	Editor   string `json:"editor"`
 }
 
-// FetchWord fetches the Wordle word for a given date
-func FetchWord(date string) (string, error) {
-	url := fmt.Sprintf("https://www.nytimes.com/svc/wordle/v2/%s.json", date)
+// NYTSource fetches the daily solution from the NYTimes Wordle API
+type NYTSource struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewNYTSource creates a new NYTimes word source, a zero timeout uses the default
+func NewNYTSource(timeout time.Duration) *NYTSource {
+	if timeout <= 0 {
+		timeout = defaultNYTTimeout
+	}
+
+	return &NYTSource{
+		client:  &http.Client{Timeout: timeout},
+		baseURL: nytBaseURL,
+	}
+}
+
+// Name returns the name of the source
+func (s *NYTSource) Name() string {
+	return "nyt"
+}
+
+// FetchPuzzle fetches the Wordle puzzle for a given date
+func (s *NYTSource) FetchPuzzle(date string) (*Puzzle, error) {
+	url := fmt.Sprintf("%s/%s.json", s.baseURL, date)
 
-	resp, err := http.Get(url)
+	resp, err := s.client.Get(url)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch wordle data: %w", err)
+		return nil, fmt.Errorf("failed to fetch wordle data: %w", err)
 	}
 
 	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			err = fmt.Errorf("error closing response body: %w", closeErr)
-		}
+		_ = resp.Body.Close()
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch wordle data: status %d", resp.StatusCode)
+		return nil, fmt.Errorf("failed to fetch wordle data: status %d", resp.StatusCode)
 	}
 
 	var wordleResp Response
 	if err := json.NewDecoder(resp.Body).Decode(&wordleResp); err != nil {
-		return "", fmt.Errorf("failed to decode wordle data: %w", err)
+		return nil, fmt.Errorf("failed to decode wordle data: %w", err)
 	}
 
-	return wordleResp.Solution, nil
-}
+	puzzle, err := newPuzzle(date, wordleResp.Solution, s.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	// Trust the NYT's own numbering when the response includes it
+	if wordleResp.Days > 0 {
+		puzzle.Number = wordleResp.Days
+	}
 
-// FetchTodayWord fetches today's Wordle word
-func FetchTodayWord() (string, error) {
-	today := time.Now().Format("2006-01-02")
-	return FetchWord(today)
+	return puzzle, nil
 }